@@ -4,6 +4,7 @@ import (
 	"context"
 	"os/exec"
 	stdruntime "runtime"
+	"sync"
 
 	wailsruntime "github.com/wailsapp/wails/v2/pkg/runtime"
 
@@ -12,6 +13,10 @@ import (
 
 type App struct {
     ctx context.Context
+
+    // cancelRun stops the match in progress; nil when none is running.
+    mu        sync.Mutex
+    cancelRun context.CancelFunc
 }
 
 func NewApp() *App {
@@ -37,6 +42,21 @@ func (a *App) SelectResumesFolder() (string, error) {
     })
 }
 
+func (a *App) SelectResumeFile() (string, error) {
+    return wailsruntime.OpenFileDialog(a.ctx, wailsruntime.OpenDialogOptions{
+        Title: "Select Resume",
+        Filters: []wailsruntime.FileFilter{
+            {DisplayName: "Documents", Pattern: "*.txt;*.text;*.md;*.pdf;*.docx;*.rtf"},
+        },
+    })
+}
+
+func (a *App) SelectJDsFolder() (string, error) {
+    return wailsruntime.OpenDirectoryDialog(a.ctx, wailsruntime.OpenDialogOptions{
+        Title: "Select Job Descriptions Folder",
+    })
+}
+
 func (a *App) SelectOutputFile() (string, error) {
 	return wailsruntime.SaveFileDialog(a.ctx, wailsruntime.SaveDialogOptions{
 		Title:           "Save Results CSV",
@@ -45,21 +65,141 @@ func (a *App) SelectOutputFile() (string, error) {
 	})
 }
 
-func (a *App) RunMatch(jdPath, resumesDir string, topN int, outPath string) (matcher.Output, error) {
+func (a *App) SelectTaxonomyFile() (string, error) {
+    return wailsruntime.OpenFileDialog(a.ctx, wailsruntime.OpenDialogOptions{
+        Title: "Select Skill Taxonomy",
+        Filters: []wailsruntime.FileFilter{
+            {DisplayName: "Taxonomy", Pattern: "*.yaml;*.yml;*.json"},
+        },
+    })
+}
+
+func (a *App) SelectRulesFile() (string, error) {
+    return wailsruntime.OpenFileDialog(a.ctx, wailsruntime.OpenDialogOptions{
+        Title: "Select Knockout Rules",
+        Filters: []wailsruntime.FileFilter{
+            {DisplayName: "Rules", Pattern: "*.yaml;*.yml;*.json"},
+        },
+    })
+}
+
+// MatchOptions carries the inputs of the desktop run form.
+type MatchOptions struct {
+    JDPath         string `json:"jdPath"`
+    ResumesDir     string `json:"resumesDir"`
+    TopN           int    `json:"topN"`
+    OutPath        string `json:"outPath"`
+    TaxonomyPath   string `json:"taxonomyPath"`
+    Profile        string `json:"profile"`
+    Weights        string `json:"weights"`
+    RulesPath      string `json:"rulesPath"`
+    KnockoutFromJD bool   `json:"knockoutFromJD"`
+    KnockoutMode   string `json:"knockoutMode"`
+    LogLevel       string `json:"logLevel"`
+}
+
+func (a *App) RunMatch(opts MatchOptions) (matcher.Output, error) {
+    topN := opts.TopN
     if topN < 0 {
         topN = 0
     }
     input := matcher.Input{
-        JDPath:     jdPath,
-        ResumesDir: resumesDir,
-        TopN:       topN,
-        OutPath:    outPath,
+        JDPath:         opts.JDPath,
+        ResumesDir:     opts.ResumesDir,
+        TopN:           topN,
+        OutPath:        opts.OutPath,
+        TaxonomyPath:   opts.TaxonomyPath,
+        Profile:        opts.Profile,
+        Weights:        opts.Weights,
+        RulesPath:      opts.RulesPath,
+        KnockoutFromJD: opts.KnockoutFromJD,
+        KnockoutMode:   opts.KnockoutMode,
+        LogLevel:       opts.LogLevel,
+        Progress:       a.emitProgress,
+    }
+
+    ctx, done := a.startRun()
+    defer done()
+    return matcher.RunHeuristic(ctx, input)
+}
+
+// JobMatchOptions carries the inputs of the desktop "rank jobs" form: one
+// resume against a folder of JDs.
+type JobMatchOptions struct {
+    ResumePath     string `json:"resumePath"`
+    JDsDir         string `json:"jdsDir"`
+    TopN           int    `json:"topN"`
+    TaxonomyPath   string `json:"taxonomyPath"`
+    Profile        string `json:"profile"`
+    Weights        string `json:"weights"`
+    RulesPath      string `json:"rulesPath"`
+    KnockoutFromJD bool   `json:"knockoutFromJD"`
+    KnockoutMode   string `json:"knockoutMode"`
+}
+
+// MatchJobs ranks the JDs of a folder for one resume. Like RunMatch it uses
+// the heuristic scorer, reports progress as "match:progress" and stops on
+// CancelMatch.
+func (a *App) MatchJobs(opts JobMatchOptions) (matcher.JobsOutput, error) {
+    topN := opts.TopN
+    if topN < 0 {
+        topN = 0
+    }
+    input := matcher.JobsInput{
+        Input: matcher.Input{
+            TopN:           topN,
+            TaxonomyPath:   opts.TaxonomyPath,
+            Profile:        opts.Profile,
+            Weights:        opts.Weights,
+            RulesPath:      opts.RulesPath,
+            KnockoutFromJD: opts.KnockoutFromJD,
+            KnockoutMode:   opts.KnockoutMode,
+            Progress:       a.emitProgress,
+        },
+        ResumePath: opts.ResumePath,
+        JDPaths:    []string{opts.JDsDir},
     }
-    return matcher.RunHeuristic(input)
+
+    ctx, done := a.startRun()
+    defer done()
+    return matcher.MatchJobsHeuristic(ctx, input)
+}
+
+func (a *App) emitProgress(p matcher.Progress) {
+    wailsruntime.EventsEmit(a.ctx, "match:progress", p)
+}
+
+// startRun makes the returned context the one CancelMatch stops; call done
+// when the run is over.
+func (a *App) startRun() (ctx context.Context, done func()) {
+    ctx, cancel := context.WithCancel(a.ctx)
+    a.mu.Lock()
+    a.cancelRun = cancel
+    a.mu.Unlock()
+    return ctx, func() {
+        a.mu.Lock()
+        a.cancelRun = nil
+        a.mu.Unlock()
+        cancel()
+    }
+}
+
+// CancelMatch stops the running match, which then fails with "context canceled".
+func (a *App) CancelMatch() {
+    a.mu.Lock()
+    defer a.mu.Unlock()
+    if a.cancelRun != nil {
+        a.cancelRun()
+    }
+}
+
+// ListProfiles returns the scoring profiles available for the profile picker.
+func (a *App) ListProfiles() ([]matcher.ScoringProfile, error) {
+    return matcher.LoadProfiles("")
 }
 
 func (a *App) EvaluateCandidate(jdPath, resumePath string) (matcher.ResumeAnalysis, error) {
-	return matcher.EvaluateCandidate(jdPath, resumePath)
+	return matcher.EvaluateCandidate(a.ctx, jdPath, resumePath)
 }
 
 // OpenResumeFile opens the resume in the default OS app.
@@ -0,0 +1,123 @@
+package main
+
+import (
+    "context"
+    "flag"
+    "fmt"
+    "os"
+    "os/signal"
+    "strings"
+    "text/tabwriter"
+
+    "resume-gpt/internal/matcher"
+)
+
+// runBatch handles "resume_matcher batch": every resume against every JD,
+// written as a candidate × job matrix plus each JD's top N.
+func runBatch(args []string) int {
+    fs := flag.NewFlagSet("batch", flag.ContinueOnError)
+    jds := fs.String("jds", "", "JD files or folders of JDs, comma-separated (or give them as arguments)")
+    resumes := fs.String("resumes", "", "Path to resumes folder")
+    topN := fs.Int("topn", 0, "Top N results written for each JD")
+    out := fs.String("out", "", "Matrix path (default: outputs/matrix.csv); each JD's results go to jobs/<jd name>/ next to it")
+    format := fs.String("format", "", "Result formats, comma-separated: csv, json, jsonl, xlsx (default: from --out extension, else csv)")
+    report := fs.String("report", "", "Also write a shortlist report for each JD: html")
+    bestFit := fs.Int("best", 0, fmt.Sprintf("Jobs listed per candidate in best_fit.csv (default: %d)", matcher.DefaultBestFit))
+    taxonomy := fs.String("taxonomy", "", "Path to skill taxonomy YAML/JSON (default: RESUMEGPT_TAXONOMY or built-in)")
+    profiles := fs.String("profiles", "", "Path to scoring profiles YAML/JSON (default: RESUMEGPT_PROFILES or built-in)")
+    profile := fs.String("profile", "", "Scoring profile name (default: default)")
+    weights := fs.String("weights", "", "Weight overrides, e.g. similarity=0.5,must=0.3")
+    rules := fs.String("rules", "", "Path to knockout rules YAML/JSON (default: RESUMEGPT_RULES)")
+    knockoutJD := fs.Bool("knockout-jd", false, "Turn each JD's minimum years, certifications and education into knockout rules")
+    knockoutMode := fs.String("knockout-mode", "", "What to do with candidates failing a knockout rule: rank (last) or exclude")
+    workers := fs.Int("workers", 0, "Parallel workers for extraction and scoring (default: RESUMEGPT_WORKERS or CPU count)")
+    logLevel := fs.String("log-level", "", "Run log level: debug, info, warn, error or off (default: RESUMEGPT_LOG_LEVEL or info)")
+    positional, err := parseArgs(fs, args)
+    if err != nil {
+        return 1
+    }
+    var jdPaths []string
+    if *jds != "" {
+        jdPaths = strings.Split(*jds, ",")
+    }
+    jdPaths = append(jdPaths, positional...)
+    if len(jdPaths) == 0 || *resumes == "" {
+        fmt.Fprintln(os.Stderr, "Usage: resume_matcher batch --jds <files or folders> --resumes <folder> [flags]")
+        return 1
+    }
+    formats, err := matcher.ParseFormats(*format)
+    if err != nil {
+        fmt.Fprintln(os.Stderr, "Invalid --format:", err)
+        return 1
+    }
+
+    input := matcher.BatchInput{
+        Input: matcher.Input{
+            ResumesDir:     *resumes,
+            TopN:           *topN,
+            OutPath:        *out,
+            TaxonomyPath:   *taxonomy,
+            ProfilesPath:   *profiles,
+            Profile:        *profile,
+            Weights:        *weights,
+            RulesPath:      *rules,
+            KnockoutFromJD: *knockoutJD,
+            KnockoutMode:   *knockoutMode,
+            Workers:        *workers,
+            Formats:        formats,
+            Report:         *report,
+            LogLevel:       *logLevel,
+        },
+        JDPaths: jdPaths,
+        BestFit: *bestFit,
+    }
+
+    ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+    go func() {
+        <-ctx.Done()
+        stop()
+    }()
+    progress := newProgressLine()
+    if progress != nil {
+        input.Progress = progress.update
+    }
+
+    result, err := matcher.RunBatch(ctx, input)
+    if progress != nil {
+        progress.clear()
+    }
+    if err != nil {
+        return exitCode(err)
+    }
+
+    if len(result.Skipped) > 0 {
+        fmt.Fprintf(os.Stderr, "Warning: skipped %d of %d resumes (see skipped.csv):\n", len(result.Skipped), result.Total)
+        for _, s := range result.Skipped {
+            fmt.Fprintf(os.Stderr, "  %s: %s\n", s.Path, s.Reason)
+        }
+    }
+    if result.Usage != nil && result.Usage.ExplainSkipped > 0 {
+        fmt.Fprintf(os.Stderr, "Warning: cost budget of $%v reached (spent $%.4f); skipped %d explanations\n", result.Usage.BudgetUSD, result.Usage.CostUSD, result.Usage.ExplainSkipped)
+    }
+
+    w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+    fmt.Fprintln(w, "JOB\tRANKED\tTOP\tRESULTS")
+    for j, job := range result.Jobs {
+        top := ""
+        for _, c := range result.Candidates {
+            if s := c.Scores[j]; s.Rank == 1 {
+                top = fmt.Sprintf("%s (%.2f)", c.Candidate, s.Score)
+                break
+            }
+        }
+        fmt.Fprintf(w, "%s\t%d\t%s\t%s\n", job.Name, job.Ranked, orNone(top), job.OutPath)
+    }
+    w.Flush()
+    fmt.Fprintln(os.Stdout, "Matrix:", result.OutPath)
+    fmt.Fprintln(os.Stdout, "Best fit:", result.Files["bestFit"])
+    if result.LogPath != "" {
+        fmt.Fprintln(os.Stdout, "Log:", result.LogPath)
+    }
+    fmt.Fprintln(os.Stdout, "Done")
+    return 0
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+    "fmt"
+    "os"
+
+    "resume-gpt/internal/matcher"
+)
+
+const cacheUsage = "Usage: resume_matcher cache info | cache clear [text|embeddings]"
+
+type cacheKind struct {
+    name  string
+    info  func() (matcher.CacheInfo, error)
+    clear func() (matcher.CacheInfo, error)
+}
+
+var cacheKinds = []cacheKind{
+    {"text", matcher.TextCacheInfo, matcher.ClearTextCache},
+    {"embeddings", matcher.EmbeddingCacheInfo, matcher.ClearEmbeddingCache},
+}
+
+// runCache handles "resume_matcher cache info" and "resume_matcher cache clear".
+func runCache(args []string) int {
+    if len(args) == 0 {
+        fmt.Fprintln(os.Stderr, cacheUsage)
+        return 1
+    }
+    switch {
+    case args[0] == "info" && len(args) == 1:
+        for _, k := range cacheKinds {
+            info, err := k.info()
+            if err != nil {
+                fmt.Fprintln(os.Stderr, "Failed to read cache:", err)
+                return 1
+            }
+            fmt.Fprintf(os.Stdout, "%-11s %s: %d entries, %s\n", k.name, info.Dir, info.Entries, formatBytes(info.Bytes))
+        }
+    case args[0] == "clear" && len(args) <= 2:
+        matched := false
+        for _, k := range cacheKinds {
+            if len(args) == 2 && args[1] != k.name {
+                continue
+            }
+            matched = true
+            info, err := k.clear()
+            if err != nil {
+                fmt.Fprintln(os.Stderr, "Failed to clear cache:", err)
+                return 1
+            }
+            fmt.Fprintf(os.Stdout, "Removed %d %s cache entries (%s) from %s\n", info.Entries, k.name, formatBytes(info.Bytes), info.Dir)
+        }
+        if !matched {
+            fmt.Fprintln(os.Stderr, cacheUsage)
+            return 1
+        }
+    default:
+        fmt.Fprintln(os.Stderr, cacheUsage)
+        return 1
+    }
+    return 0
+}
+
+func formatBytes(n int64) string {
+    switch {
+    case n >= 1<<20:
+        return fmt.Sprintf("%.1f MB", float64(n)/(1<<20))
+    case n >= 1<<10:
+        return fmt.Sprintf("%.1f KB", float64(n)/(1<<10))
+    default:
+        return fmt.Sprintf("%d B", n)
+    }
+}
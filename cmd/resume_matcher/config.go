@@ -0,0 +1,85 @@
+package main
+
+import (
+    "flag"
+    "fmt"
+    "os"
+    "strconv"
+
+    "resume-gpt/internal/matcher"
+)
+
+const configUsage = "Usage: resume_matcher config show [--json] [rank flags]"
+
+// runConfig handles "resume_matcher config show": the value of every setting
+// and where it came from, with flags > env > project config > user config >
+// defaults. Rank flags given here show up with the "flag" source.
+func runConfig(args []string) int {
+    if len(args) == 0 || args[0] != "show" {
+        fmt.Fprintln(os.Stderr, configUsage)
+        return 1
+    }
+    fs := flag.NewFlagSet("config show", flag.ContinueOnError)
+    asJSON := fs.Bool("json", false, "Print the settings as JSON")
+    flagged := map[string]*string{
+        "RESUMEGPT_TAXONOMY":  fs.String("taxonomy", "", "Path to skill taxonomy YAML/JSON"),
+        "RESUMEGPT_PROFILES":  fs.String("profiles", "", "Path to scoring profiles YAML/JSON"),
+        "RESUMEGPT_PROFILE":   fs.String("profile", "", "Scoring profile name"),
+        "RESUMEGPT_WEIGHTS":   fs.String("weights", "", "Weight overrides"),
+        "RESUMEGPT_RULES":     fs.String("rules", "", "Path to knockout rules YAML/JSON"),
+        "RESUMEGPT_FORMATS":   fs.String("format", "", "Result formats, comma-separated"),
+        "RESUMEGPT_REPORT":    fs.String("report", "", "Shortlist report: html"),
+        "RESUMEGPT_LOG_LEVEL": fs.String("log-level", "", "Run log level"),
+    }
+    workers := fs.Int("workers", 0, "Parallel workers for extraction and scoring")
+    if err := fs.Parse(args[1:]); err != nil {
+        return 1
+    }
+    if fs.NArg() > 0 {
+        fmt.Fprintln(os.Stderr, configUsage)
+        return 1
+    }
+    if *workers > 0 {
+        w := strconv.Itoa(*workers)
+        flagged["RESUMEGPT_WORKERS"] = &w
+    }
+
+    settings := matcher.Settings()
+    for i, s := range settings {
+        if v, ok := flagged[s.Key]; ok && *v != "" {
+            settings[i].Value = *v
+            settings[i].Source = matcher.SourceFlag
+        }
+    }
+    project, user := matcher.ConfigPaths()
+    envPath := matcher.DotEnvPath()
+    configErr := ""
+    if err := matcher.ConfigError(); err != nil {
+        configErr = err.Error()
+    }
+    if *asJSON {
+        return printJSON(struct {
+            ProjectConfig string            `json:"projectConfig"`
+            UserConfig    string            `json:"userConfig"`
+            DotEnv        string            `json:"dotEnv"`
+            Error         string            `json:"error,omitempty"`
+            Settings      []matcher.Setting `json:"settings"`
+        }{project, user, envPath, configErr, settings})
+    }
+    fmt.Println("Project config:", orNone(project))
+    if user == "" {
+        fmt.Println("User config:    (none, looked up at " + orNone(matcher.UserConfigPath()) + ")")
+    } else {
+        fmt.Println("User config:   ", user)
+    }
+    fmt.Println(".env file:     ", orNone(envPath))
+    if configErr != "" {
+        fmt.Println("Error:         ", configErr)
+    }
+    fmt.Println()
+    printSettings(settings)
+    if configErr != "" {
+        return 7
+    }
+    return 0
+}
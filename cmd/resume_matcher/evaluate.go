@@ -0,0 +1,59 @@
+package main
+
+import (
+    "context"
+    "flag"
+    "fmt"
+    "os"
+    "os/signal"
+    "strings"
+
+    "resume-gpt/internal/matcher"
+)
+
+// runEvaluate handles "resume_matcher evaluate": the desktop app's Evaluate
+// button for a single resume.
+func runEvaluate(args []string) int {
+    fs := flag.NewFlagSet("evaluate", flag.ContinueOnError)
+    jd := fs.String("jd", "", "Path to job description file")
+    resume := fs.String("resume", "", "Path to the resume to evaluate")
+    asJSON := fs.Bool("json", false, "Print the analysis as JSON")
+    if err := fs.Parse(args); err != nil {
+        return 1
+    }
+    if *jd == "" || *resume == "" || fs.NArg() > 0 {
+        fmt.Fprintln(os.Stderr, "Usage: resume_matcher evaluate --jd <file> --resume <file> [--json]")
+        return 1
+    }
+
+    ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+    defer stop()
+    analysis, err := matcher.EvaluateCandidate(ctx, *jd, *resume)
+    if err != nil {
+        return exitCode(err)
+    }
+    if *asJSON {
+        return printJSON(analysis)
+    }
+    fmt.Println(analysis.Summary)
+    fmt.Println()
+    printList("Strengths", analysis.Strengths)
+    printList("Weaknesses", analysis.Weaknesses)
+    printList("Skills", analysis.Skills)
+    if analysis.YearsExperience > 0 {
+        fmt.Printf("Experience:     %g years\n", analysis.YearsExperience)
+    }
+    printList("Education", analysis.Education)
+    printList("Certifications", analysis.Certifications)
+    printList("Titles", analysis.Titles)
+    return 0
+}
+
+// printList prints a labeled, comma-separated list, or nothing when it is
+// empty.
+func printList(label string, items []string) {
+    if len(items) == 0 {
+        return
+    }
+    fmt.Printf("%-15s %s\n", label+":", strings.Join(items, ", "))
+}
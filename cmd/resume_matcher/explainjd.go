@@ -0,0 +1,63 @@
+package main
+
+import (
+    "context"
+    "flag"
+    "fmt"
+    "os"
+    "os/signal"
+
+    "resume-gpt/internal/matcher"
+)
+
+// runExplainJD handles "resume_matcher explain-jd": prints the requirements
+// a run would read from a JD.
+func runExplainJD(args []string) int {
+    fs := flag.NewFlagSet("explain-jd", flag.ContinueOnError)
+    jd := fs.String("jd", "", "Path to job description file")
+    taxonomy := fs.String("taxonomy", "", "Path to skill taxonomy YAML/JSON (default: RESUMEGPT_TAXONOMY or built-in)")
+    heuristic := fs.Bool("heuristic", false, "Parse the JD without calling the provider")
+    asJSON := fs.Bool("json", false, "Print the requirements as JSON")
+    files, err := parseArgs(fs, args)
+    if err != nil {
+        return 1
+    }
+    if *jd == "" && len(files) == 1 {
+        *jd = files[0]
+        files = nil
+    }
+    if *jd == "" || len(files) > 0 {
+        fmt.Fprintln(os.Stderr, "Usage: resume_matcher explain-jd [--heuristic] [--taxonomy <file>] [--json] --jd <file>")
+        return 1
+    }
+
+    ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+    defer stop()
+    res, err := matcher.ExplainJD(ctx, *jd, *taxonomy, *heuristic)
+    if err != nil {
+        return exitCode(err)
+    }
+    if *asJSON {
+        return printJSON(res)
+    }
+    fmt.Printf("Mode:           %s\n", runMode(res.Mode, res.Provider))
+    if res.JD.RoleTitle != "" {
+        fmt.Printf("Role:           %s\n", res.JD.RoleTitle)
+    }
+    fmt.Printf("Must have:      %s\n", orNone(joinList(res.JD.SkillsMust)))
+    fmt.Printf("Nice to have:   %s\n", orNone(joinList(res.JD.SkillsNice)))
+    fmt.Printf("Other skills:   %s\n", orNone(joinList(res.JD.SkillsOther)))
+    if res.JD.YearsExperienceMin > 0 {
+        fmt.Printf("Min experience: %g years\n", res.JD.YearsExperienceMin)
+    }
+    printList("Education", res.JD.Education)
+    printList("Certifications", res.JD.Certifications)
+    printList("Titles", res.JD.Titles)
+    if len(res.JD.Responsibilities) > 0 {
+        fmt.Println("Responsibilities:")
+        for _, r := range res.JD.Responsibilities {
+            fmt.Printf("  - %s\n", r)
+        }
+    }
+    return 0
+}
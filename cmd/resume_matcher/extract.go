@@ -0,0 +1,31 @@
+package main
+
+import (
+    "flag"
+    "fmt"
+    "os"
+
+    "resume-gpt/internal/matcher"
+)
+
+// runExtract handles "resume_matcher extract": prints the text the matcher
+// reads from a file, to check a PDF or DOCX that scores oddly.
+func runExtract(args []string) int {
+    fs := flag.NewFlagSet("extract", flag.ContinueOnError)
+    redact := fs.Bool("redact", false, "Redact the text as RESUMEGPT_REDACT does before sending it to a provider")
+    files, err := parseArgs(fs, args)
+    if err != nil {
+        return 1
+    }
+    if len(files) != 1 {
+        fmt.Fprintln(os.Stderr, "Usage: resume_matcher extract [--redact] <file>")
+        return 1
+    }
+
+    text, err := matcher.ExtractText(files[0], *redact)
+    if err != nil {
+        return exitCode(err)
+    }
+    fmt.Fprintln(os.Stdout, text)
+    return 0
+}
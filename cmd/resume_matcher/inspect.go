@@ -0,0 +1,47 @@
+package main
+
+import (
+    "flag"
+    "fmt"
+    "os"
+    "text/tabwriter"
+
+    "resume-gpt/internal/matcher"
+)
+
+// runInspect handles "resume_matcher inspect": where the .env file was found
+// and the value and source of every setting.
+func runInspect(args []string) int {
+    fs := flag.NewFlagSet("inspect", flag.ContinueOnError)
+    asJSON := fs.Bool("json", false, "Print the settings as JSON")
+    if err := fs.Parse(args); err != nil {
+        return 1
+    }
+    if fs.NArg() > 0 {
+        fmt.Fprintln(os.Stderr, "Usage: resume_matcher inspect [--json]")
+        return 1
+    }
+
+    settings := matcher.Settings()
+    envPath := matcher.DotEnvPath()
+    if *asJSON {
+        return printJSON(struct {
+            DotEnv   string            `json:"dotEnv"`
+            Settings []matcher.Setting `json:"settings"`
+        }{envPath, settings})
+    }
+    fmt.Println(".env file:", orNone(envPath))
+    fmt.Println()
+    printSettings(settings)
+    return 0
+}
+
+// printSettings prints the settings of inspect and config show as a table.
+func printSettings(settings []matcher.Setting) {
+    w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+    fmt.Fprintln(w, "SETTING\tVALUE\tSOURCE")
+    for _, s := range settings {
+        fmt.Fprintf(w, "%s\t%s\t%s\n", s.Key, orNone(s.Value), s.Source)
+    }
+    w.Flush()
+}
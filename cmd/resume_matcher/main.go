@@ -1,78 +1,164 @@
 package main
 
 import (
+    "context"
     "errors"
     "flag"
     "fmt"
     "os"
+    "strings"
 
     "resume-gpt/internal/matcher"
 )
 
+const usage = `Usage: resume_matcher <command> [flags]
+
+Commands:
+  rank        rank a resumes folder against a JD (the default when only flags are given)
+  batch       rank a resumes folder against several JDs into a candidate × job matrix
+  match-jobs  rank a folder of JDs for one resume
+  evaluate    evaluate one resume against a JD
+  extract     print the text extracted from a resume or JD
+  explain-jd  print the requirements read from a JD
+  inspect     show the .env file and the settings in effect
+  config      show the effective settings and the config files they came from
+  runs        list, show and diff saved runs
+  cache       show or clear the text and embedding caches
+  serve       run the HTTP API
+
+Run "resume_matcher <command> -h" for the flags of a command.`
+
+var commands = map[string]func(args []string) int{
+    "rank":       runRank,
+    "batch":      runBatch,
+    "match-jobs": runMatchJobs,
+    "evaluate":   runEvaluate,
+    "extract":    runExtract,
+    "explain-jd": runExplainJD,
+    "inspect":    runInspect,
+    "config":     runConfig,
+    "runs":       runRuns,
+    "cache":      runCache,
+    "serve":      runServe,
+}
+
 func main() {
     matcher.LoadDotEnv()
-    workbook := flag.String("workbook", "", "Path to Excel workbook")
-    jd := flag.String("jd", "", "Path to job description file")
-    resumes := flag.String("resumes", "", "Path to resumes folder")
-    topN := flag.Int("topn", 0, "Top N results")
-    out := flag.String("out", "", "Output CSV path")
-    flag.Parse()
-
-    var input matcher.Input
+    args := os.Args[1:]
+    // A bare flag list is a rank, which is how the workbook macro and older
+    // scripts call the matcher.
+    if len(args) == 0 || strings.HasPrefix(args[0], "-") {
+        os.Exit(runRank(args))
+    }
+    if args[0] == "help" {
+        fmt.Fprintln(os.Stdout, usage)
+        return
+    }
+    cmd, ok := commands[args[0]]
+    if !ok {
+        fmt.Fprintf(os.Stderr, "Unknown command %q\n\n%s\n", args[0], usage)
+        os.Exit(1)
+    }
+    os.Exit(cmd(args[1:]))
+}
 
-    if *workbook != "" {
-        if _, err := os.Stat(*workbook); err != nil {
-            fmt.Fprintln(os.Stderr, "Workbook not found")
-            os.Exit(1)
-        }
-        wbInput, err := matcher.ReadWorkbookInputs(*workbook)
-        if err != nil {
-            fmt.Fprintln(os.Stderr, "Failed to read workbook:", err)
-            os.Exit(1)
+// parseArgs parses flags given before or after the positional arguments,
+// which it returns.
+func parseArgs(fs *flag.FlagSet, args []string) ([]string, error) {
+    var positional []string
+    for {
+        if err := fs.Parse(args); err != nil {
+            return nil, err
         }
-        input = wbInput
-    } else {
-        input = matcher.Input{
-            JDPath:     *jd,
-            ResumesDir: *resumes,
-            TopN:       *topN,
-            OutPath:    *out,
-        }
-        if input.JDPath == "" || input.ResumesDir == "" {
-            fmt.Fprintln(os.Stderr, "Provide --workbook or both --jd and --resumes")
-            os.Exit(1)
+        if fs.NArg() == 0 {
+            return positional, nil
         }
+        positional = append(positional, fs.Arg(0))
+        args = fs.Args()[1:]
     }
+}
 
-    _, err := matcher.Run(input)
-    if err != nil {
-        switch {
-        case errors.Is(err, matcher.ErrMissingJD):
-            fmt.Fprintln(os.Stderr, "Job description file not found")
-            os.Exit(2)
-        case errors.Is(err, matcher.ErrReadJD):
-            fmt.Fprintln(os.Stderr, "Failed to read JD:", err)
-            os.Exit(2)
-        case errors.Is(err, matcher.ErrMissingResumes):
-            fmt.Fprintln(os.Stderr, "Resumes folder not found")
-            os.Exit(3)
-        case errors.Is(err, matcher.ErrListResumes):
-            fmt.Fprintln(os.Stderr, "Failed to list resumes:", err)
-            os.Exit(3)
-        case errors.Is(err, matcher.ErrNoResumes):
-            fmt.Fprintln(os.Stderr, "No resumes found")
-            os.Exit(4)
-		case errors.Is(err, matcher.ErrWriteResults):
-			fmt.Fprintln(os.Stderr, "Failed to write results:", err)
-			os.Exit(5)
-		case errors.Is(err, matcher.ErrMissingOpenAIKey):
-			fmt.Fprintln(os.Stderr, "OpenAI API key not configured (set OPENAI_API_KEY)")
-			os.Exit(6)
-		default:
-			fmt.Fprintln(os.Stderr, "Matcher failed:", err)
-			os.Exit(5)
-		}
-	}
+func joinList(items []string) string {
+    return strings.Join(items, ", ")
+}
 
-    fmt.Fprintln(os.Stdout, "Done")
-}
+// exitCode reports err on stderr and returns the process exit code for it.
+func exitCode(err error) int {
+    switch {
+    case errors.Is(err, matcher.ErrMissingJD):
+        fmt.Fprintln(os.Stderr, "Job description file not found")
+        return 2
+    case errors.Is(err, matcher.ErrReadJD):
+        fmt.Fprintln(os.Stderr, "Failed to read JD:", err)
+        return 2
+    case errors.Is(err, matcher.ErrMissingResumes):
+        fmt.Fprintln(os.Stderr, "Resumes folder not found")
+        return 3
+    case errors.Is(err, matcher.ErrListResumes):
+        fmt.Fprintln(os.Stderr, "Failed to list resumes:", err)
+        return 3
+    case errors.Is(err, matcher.ErrNoResumes):
+        fmt.Fprintln(os.Stderr, "No resumes found:", err)
+        return 4
+    case errors.Is(err, matcher.ErrMissingResume):
+        fmt.Fprintln(os.Stderr, "Resume file not found")
+        return 3
+    case errors.Is(err, matcher.ErrReadResume):
+        fmt.Fprintln(os.Stderr, "Failed to read resume:", err)
+        return 3
+    case errors.Is(err, matcher.ErrExtract):
+        fmt.Fprintln(os.Stderr, err)
+        return 2
+    case errors.Is(err, matcher.ErrLoadConfig):
+        fmt.Fprintln(os.Stderr, "Invalid config file:", err)
+        return 7
+    case errors.Is(err, matcher.ErrLoadTaxonomy):
+        fmt.Fprintln(os.Stderr, "Failed to load skill taxonomy:", err)
+        return 7
+    case errors.Is(err, matcher.ErrLoadProfiles),
+        errors.Is(err, matcher.ErrUnknownProfile),
+        errors.Is(err, matcher.ErrInvalidWeights):
+        fmt.Fprintln(os.Stderr, "Invalid scoring profile:", err)
+        return 7
+    case errors.Is(err, matcher.ErrLoadRules),
+        errors.Is(err, matcher.ErrKnockoutMode):
+        fmt.Fprintln(os.Stderr, "Invalid knockout rules:", err)
+        return 7
+    case errors.Is(err, matcher.ErrLogLevel):
+        fmt.Fprintln(os.Stderr, "Invalid --log-level:", err)
+        return 1
+    case errors.Is(err, matcher.ErrFormat):
+        fmt.Fprintln(os.Stderr, "Invalid output format:", err)
+        return 1
+    case errors.Is(err, matcher.ErrWriteResults):
+        fmt.Fprintln(os.Stderr, "Failed to write results:", err)
+        return 5
+    case errors.Is(err, matcher.ErrMissingOpenAIKey):
+        fmt.Fprintln(os.Stderr, "API key not configured:", err)
+        return 6
+    case errors.Is(err, matcher.ErrProvider):
+        fmt.Fprintln(os.Stderr, "Invalid provider settings:", err)
+        return 14
+    case errors.Is(err, matcher.ErrProviderAuth):
+        fmt.Fprintln(os.Stderr, "The provider rejected the API key:", err)
+        return 9
+    case errors.Is(err, matcher.ErrProviderQuota):
+        fmt.Fprintln(os.Stderr, "Provider quota exceeded:", err)
+        return 10
+    case errors.Is(err, matcher.ErrProviderRateLimited):
+        fmt.Fprintln(os.Stderr, "Provider rate limit exceeded:", err)
+        return 11
+    case errors.Is(err, matcher.ErrProviderBadRequest):
+        fmt.Fprintln(os.Stderr, "The provider rejected the request:", err)
+        return 12
+    case errors.Is(err, matcher.ErrProviderUnavailable):
+        fmt.Fprintln(os.Stderr, "Provider unavailable:", err)
+        return 13
+    case errors.Is(err, context.Canceled):
+        fmt.Fprintln(os.Stderr, "Canceled")
+        return 130
+    default:
+        fmt.Fprintln(os.Stderr, "Matcher failed:", err)
+        return 5
+    }
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+    "context"
+    "flag"
+    "fmt"
+    "os"
+    "os/signal"
+    "strings"
+    "text/tabwriter"
+
+    "resume-gpt/internal/matcher"
+)
+
+// runMatchJobs handles "resume_matcher match-jobs": the reverse of rank, one
+// resume against a folder of JDs with the JDs ranked for the candidate.
+func runMatchJobs(args []string) int {
+    fs := flag.NewFlagSet("match-jobs", flag.ContinueOnError)
+    resume := fs.String("resume", "", "Path to the candidate's resume")
+    jds := fs.String("jds", "", "JD files or folders of JDs, comma-separated (or give them as arguments)")
+    topN := fs.Int("topn", 0, "Only list the top N jobs (default: all)")
+    heuristic := fs.Bool("heuristic", false, "Rank with the heuristic scorer without calling the provider")
+    taxonomy := fs.String("taxonomy", "", "Path to skill taxonomy YAML/JSON (default: RESUMEGPT_TAXONOMY or built-in)")
+    profiles := fs.String("profiles", "", "Path to scoring profiles YAML/JSON (default: RESUMEGPT_PROFILES or built-in)")
+    profile := fs.String("profile", "", "Scoring profile name (default: default)")
+    weights := fs.String("weights", "", "Weight overrides, e.g. similarity=0.5,must=0.3")
+    rules := fs.String("rules", "", "Path to knockout rules YAML/JSON (default: RESUMEGPT_RULES)")
+    knockoutJD := fs.Bool("knockout-jd", false, "Turn each JD's minimum years, certifications and education into knockout rules")
+    knockoutMode := fs.String("knockout-mode", "", "What to do with jobs the candidate fails a knockout rule for: rank (last) or exclude")
+    workers := fs.Int("workers", 0, "Parallel workers for extraction and scoring (default: RESUMEGPT_WORKERS or CPU count)")
+    asJSON := fs.Bool("json", false, "Print the ranked jobs as JSON")
+    positional, err := parseArgs(fs, args)
+    if err != nil {
+        return 1
+    }
+    var jdPaths []string
+    if *jds != "" {
+        jdPaths = strings.Split(*jds, ",")
+    }
+    jdPaths = append(jdPaths, positional...)
+    if *resume == "" || len(jdPaths) == 0 {
+        fmt.Fprintln(os.Stderr, "Usage: resume_matcher match-jobs --resume <file> --jds <files or folders> [flags]")
+        return 1
+    }
+
+    input := matcher.JobsInput{
+        Input: matcher.Input{
+            TopN:           *topN,
+            TaxonomyPath:   *taxonomy,
+            ProfilesPath:   *profiles,
+            Profile:        *profile,
+            Weights:        *weights,
+            RulesPath:      *rules,
+            KnockoutFromJD: *knockoutJD,
+            KnockoutMode:   *knockoutMode,
+            Workers:        *workers,
+        },
+        ResumePath: *resume,
+        JDPaths:    jdPaths,
+    }
+
+    ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+    defer stop()
+    progress := newProgressLine()
+    if progress != nil && !*asJSON {
+        input.Progress = progress.update
+    }
+
+    match := matcher.MatchJobs
+    if *heuristic {
+        match = matcher.MatchJobsHeuristic
+    }
+    result, err := match(ctx, input)
+    if progress != nil {
+        progress.clear()
+    }
+    if err != nil {
+        return exitCode(err)
+    }
+    if *asJSON {
+        return printJSON(result)
+    }
+
+    if result.Warning != "" {
+        fmt.Fprintln(os.Stderr, "Warning:", result.Warning)
+    }
+    if result.Usage != nil && result.Usage.ExplainSkipped > 0 {
+        fmt.Fprintf(os.Stderr, "Warning: cost budget of $%v reached (spent $%.4f); skipped %d explanations\n", result.Usage.BudgetUSD, result.Usage.CostUSD, result.Usage.ExplainSkipped)
+    }
+    mode := result.Mode
+    if result.Provider != "" {
+        mode = result.Provider
+    }
+    fmt.Printf("Candidate: %s (%s, profile %s)\n\n", result.Candidate, mode, result.Profile)
+    w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+    fmt.Fprintln(w, "RANK\tJOB\tSCORE\tMUST\tNICE\tKNOCKED OUT")
+    for _, j := range result.Jobs {
+        b := j.Breakdown
+        knockedOut := ""
+        if j.KnockedOut {
+            knockedOut = strings.Join(j.FailedRules, "; ")
+        }
+        fmt.Fprintf(w, "%d\t%s\t%.2f\t%d/%d\t%d/%d\t%s\n", j.Rank, j.Job, j.Score,
+            len(b.MatchedMust), len(b.MatchedMust)+len(b.MissingMust),
+            len(b.MatchedNice), len(b.MatchedNice)+len(b.MissingNice), knockedOut)
+    }
+    w.Flush()
+    for _, j := range result.Jobs {
+        fmt.Printf("\n%d. %s (%s)\n", j.Rank, j.Job, j.JDPath)
+        printList("Matched must", j.Breakdown.MatchedMust)
+        printList("Missing must", j.Breakdown.MissingMust)
+        printList("Matched nice", j.Breakdown.MatchedNice)
+        printList("Missing nice", j.Breakdown.MissingNice)
+        if j.Explanation != "" {
+            fmt.Printf("%-15s %s\n", "Explanation:", j.Explanation)
+        }
+        if j.Warning != "" && j.Warning != result.Warning {
+            fmt.Printf("%-15s %s\n", "Warning:", j.Warning)
+        }
+    }
+    if result.Excluded > 0 {
+        fmt.Printf("\nExcluded %d of %d jobs by knockout rules\n", result.Excluded, result.Total)
+    }
+    return 0
+}
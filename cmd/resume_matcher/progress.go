@@ -0,0 +1,81 @@
+package main
+
+import (
+    "fmt"
+    "os"
+    "path/filepath"
+    "strings"
+    "sync"
+    "time"
+
+    "resume-gpt/internal/matcher"
+)
+
+// progressLine redraws a single status line on stderr. It is only used when
+// stderr is a terminal, so the Excel macro, which reads stderr for warnings,
+// never sees it.
+type progressLine struct {
+    mu      sync.Mutex
+    last    time.Time
+    lastLen int
+}
+
+var stageLabels = map[string]string{
+    matcher.StageExtract: "Reading resumes",
+    matcher.StageJD:      "Reading job description",
+    matcher.StageEmbed:   "Embedding",
+    matcher.StageScore:   "Scoring",
+    matcher.StageExplain: "Explaining top candidates",
+    matcher.StageWrite:   "Writing results",
+}
+
+func newProgressLine() *progressLine {
+    fi, err := os.Stderr.Stat()
+    if err != nil || fi.Mode()&os.ModeCharDevice == 0 {
+        return nil
+    }
+    return &progressLine{}
+}
+
+// update draws p, at most ten times a second unless a stage starts or ends.
+func (l *progressLine) update(p matcher.Progress) {
+    l.mu.Lock()
+    defer l.mu.Unlock()
+    now := time.Now()
+    if p.Done != 0 && p.Done != p.Total && now.Sub(l.last) < 100*time.Millisecond {
+        return
+    }
+    l.last = now
+
+    label := stageLabels[p.Stage]
+    if label == "" {
+        label = p.Stage
+    }
+    text := fmt.Sprintf("%s %d/%d", label, p.Done, p.Total)
+    if p.File != "" {
+        text += " " + filepath.Base(p.File)
+    }
+    if len(text) > 79 {
+        text = text[:79]
+    }
+    l.draw(text)
+}
+
+// clear erases the line so the messages after the run start on a clean line.
+func (l *progressLine) clear() {
+    l.mu.Lock()
+    defer l.mu.Unlock()
+    l.draw("")
+}
+
+func (l *progressLine) draw(text string) {
+    pad := l.lastLen - len(text)
+    if pad < 0 {
+        pad = 0
+    }
+    fmt.Fprint(os.Stderr, "\r"+text+strings.Repeat(" ", pad))
+    if text == "" {
+        fmt.Fprint(os.Stderr, "\r")
+    }
+    l.lastLen = len(text)
+}
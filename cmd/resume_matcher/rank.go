@@ -0,0 +1,157 @@
+package main
+
+import (
+    "context"
+    "flag"
+    "fmt"
+    "os"
+    "os/signal"
+
+    "resume-gpt/internal/matcher"
+)
+
+// runRank handles "resume_matcher rank", which is also what a bare flag list
+// runs, as the workbook macro calls it.
+func runRank(args []string) int {
+    fs := flag.NewFlagSet("rank", flag.ContinueOnError)
+    workbook := fs.String("workbook", "", "Path to Excel workbook")
+    jd := fs.String("jd", "", "Path to job description file")
+    resumes := fs.String("resumes", "", "Path to resumes folder")
+    topN := fs.Int("topn", 0, "Top N results")
+    out := fs.String("out", "", "Output CSV path")
+    format := fs.String("format", "", "Result formats, comma-separated: csv, json, jsonl, xlsx (default: from --out extension, else csv)")
+    report := fs.String("report", "", "Also write a shortlist report: html (written next to --out, e.g. results.html)")
+    updateWorkbook := fs.Bool("update-workbook", false, "Also write the results into the Results sheet of --workbook (close it in Excel first)")
+    taxonomy := fs.String("taxonomy", "", "Path to skill taxonomy YAML/JSON (default: RESUMEGPT_TAXONOMY or built-in)")
+    profiles := fs.String("profiles", "", "Path to scoring profiles YAML/JSON (default: RESUMEGPT_PROFILES or built-in)")
+    profile := fs.String("profile", "", "Scoring profile name (default: default)")
+    weights := fs.String("weights", "", "Weight overrides, e.g. similarity=0.5,must=0.3,nice=0.1,skills=0.1,experience=0")
+    rules := fs.String("rules", "", "Path to knockout rules YAML/JSON (default: RESUMEGPT_RULES)")
+    knockoutJD := fs.Bool("knockout-jd", false, "Turn the JD's minimum years, certifications and education into knockout rules")
+    knockoutMode := fs.String("knockout-mode", "", "What to do with candidates failing a knockout rule: rank (last) or exclude")
+    workers := fs.Int("workers", 0, "Parallel workers for extraction and scoring (default: RESUMEGPT_WORKERS or CPU count)")
+    strict := fs.Bool("strict", false, "Exit with code 8 when any resume could not be read")
+    logLevel := fs.String("log-level", "", "Run log level: debug, info, warn, error or off (default: RESUMEGPT_LOG_LEVEL or info)")
+    if err := fs.Parse(args); err != nil {
+        return 1
+    }
+
+    var input matcher.Input
+
+    if *workbook != "" {
+        if _, err := os.Stat(*workbook); err != nil {
+            fmt.Fprintln(os.Stderr, "Workbook not found")
+            return 1
+        }
+        wbInput, err := matcher.ReadWorkbookInputs(*workbook)
+        if err != nil {
+            fmt.Fprintln(os.Stderr, "Failed to read workbook:", err)
+            return 1
+        }
+        input = wbInput
+        if *taxonomy != "" {
+            input.TaxonomyPath = *taxonomy
+        }
+        if *profile != "" {
+            input.Profile = *profile
+        }
+        if *weights != "" {
+            input.Weights = *weights
+        }
+        if *rules != "" {
+            input.RulesPath = *rules
+        }
+        if *knockoutMode != "" {
+            input.KnockoutMode = *knockoutMode
+        }
+        if *updateWorkbook {
+            input.ResultsWorkbook = *workbook
+        }
+    } else {
+        input = matcher.Input{
+            JDPath:       *jd,
+            ResumesDir:   *resumes,
+            TopN:         *topN,
+            OutPath:      *out,
+            TaxonomyPath: *taxonomy,
+            Profile:      *profile,
+            Weights:      *weights,
+            RulesPath:    *rules,
+            KnockoutMode: *knockoutMode,
+        }
+        if input.JDPath == "" || input.ResumesDir == "" {
+            fmt.Fprintln(os.Stderr, "Provide --workbook or both --jd and --resumes")
+            return 1
+        }
+        if *updateWorkbook {
+            fmt.Fprintln(os.Stderr, "--update-workbook needs --workbook")
+            return 1
+        }
+    }
+    input.ProfilesPath = *profiles
+    input.Workers = *workers
+    formats, err := matcher.ParseFormats(*format)
+    if err != nil {
+        fmt.Fprintln(os.Stderr, "Invalid --format:", err)
+        return 1
+    }
+    if len(formats) > 0 {
+        input.Formats = formats
+    }
+    if *report != "" {
+        input.Report = *report
+    }
+    if *knockoutJD {
+        input.KnockoutFromJD = true
+    }
+    if *logLevel != "" {
+        input.LogLevel = *logLevel
+    }
+
+    // The first Ctrl-C cancels the run; a second one kills the process.
+    ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+    go func() {
+        <-ctx.Done()
+        stop()
+    }()
+    progress := newProgressLine()
+    if progress != nil {
+        input.Progress = progress.update
+    }
+
+    result, err := matcher.Run(ctx, input)
+    if progress != nil {
+        progress.clear()
+    }
+    if err != nil {
+        return exitCode(err)
+    }
+
+    if len(result.Skipped) > 0 {
+        fmt.Fprintf(os.Stderr, "Warning: skipped %d of %d resumes (see skipped.csv):\n", len(result.Skipped), result.Total)
+        for _, s := range result.Skipped {
+            fmt.Fprintf(os.Stderr, "  %s: %s\n", s.Path, s.Reason)
+        }
+    }
+    if len(result.Flagged) > 0 {
+        fmt.Fprintf(os.Stderr, "Warning: %d resumes have very little text:\n", len(result.Flagged))
+        for _, s := range result.Flagged {
+            fmt.Fprintf(os.Stderr, "  %s: %s\n", s.Path, s.Reason)
+        }
+    }
+    if result.Usage != nil && result.Usage.ExplainSkipped > 0 {
+        fmt.Fprintf(os.Stderr, "Warning: cost budget of $%v reached (spent $%.4f); skipped %d explanations\n", result.Usage.BudgetUSD, result.Usage.CostUSD, result.Usage.ExplainSkipped)
+    }
+
+    if result.RunID != "" {
+        fmt.Fprintln(os.Stdout, "Run ID:", result.RunID)
+    }
+    if result.LogPath != "" {
+        fmt.Fprintln(os.Stdout, "Log:", result.LogPath)
+    }
+    fmt.Fprintln(os.Stdout, "Done")
+    if *strict && len(result.Skipped) > 0 {
+        return 8
+    }
+    return 0
+}
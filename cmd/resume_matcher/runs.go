@@ -0,0 +1,199 @@
+package main
+
+import (
+    "encoding/json"
+    "errors"
+    "fmt"
+    "os"
+    "path/filepath"
+    "sort"
+    "text/tabwriter"
+
+    "resume-gpt/internal/matcher"
+)
+
+const runsUsage = "Usage: resume_matcher runs list | runs show <id> | runs diff <old-id> <new-id>  (add --json for JSON; ids may be prefixes or \"latest\")"
+
+// runRuns handles "resume_matcher runs list|show|diff".
+func runRuns(args []string) int {
+    asJSON := false
+    rest := args[:0:0]
+    for _, a := range args {
+        if a == "--json" || a == "-json" {
+            asJSON = true
+            continue
+        }
+        rest = append(rest, a)
+    }
+    if len(rest) == 0 {
+        fmt.Fprintln(os.Stderr, runsUsage)
+        return 1
+    }
+
+    switch {
+    case rest[0] == "list" && len(rest) == 1:
+        runs, err := matcher.ListRuns()
+        if err != nil {
+            fmt.Fprintln(os.Stderr, "Failed to read run history:", err)
+            return 1
+        }
+        if asJSON {
+            return printJSON(runs)
+        }
+        if len(runs) == 0 {
+            fmt.Fprintln(os.Stdout, "No runs saved in", matcher.HistoryDir())
+            return 0
+        }
+        w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+        fmt.Fprintln(w, "ID\tDATE\tMODE\tPROFILE\tJD\tRANKED\tTOP")
+        for _, r := range runs {
+            top := ""
+            if r.Top != "" {
+                top = fmt.Sprintf("%s (%.2f)", r.Top, r.TopScore)
+            }
+            fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%d/%d\t%s\n", r.ID, r.GeneratedAt.Local().Format("2006-01-02 15:04"), runMode(r.Mode, r.Provider), r.Profile, filepath.Base(r.JDPath), r.Ranked, r.Total, top)
+        }
+        w.Flush()
+    case rest[0] == "show" && len(rest) == 2:
+        rec, code := loadRun(rest[1])
+        if code != 0 {
+            return code
+        }
+        if asJSON {
+            return printJSON(rec)
+        }
+        printRun(rec)
+    case rest[0] == "diff" && len(rest) == 3:
+        from, code := loadRun(rest[1])
+        if code != 0 {
+            return code
+        }
+        to, code := loadRun(rest[2])
+        if code != 0 {
+            return code
+        }
+        diff := matcher.DiffRuns(from, to)
+        if asJSON {
+            return printJSON(diff)
+        }
+        printDiff(diff)
+    default:
+        fmt.Fprintln(os.Stderr, runsUsage)
+        return 1
+    }
+    return 0
+}
+
+func loadRun(id string) (matcher.RunRecord, int) {
+    rec, err := matcher.LoadRun(id)
+    if err != nil {
+        if errors.Is(err, matcher.ErrRunNotFound) || errors.Is(err, matcher.ErrRunAmbiguous) {
+            fmt.Fprintln(os.Stderr, err)
+        } else {
+            fmt.Fprintln(os.Stderr, "Failed to read run:", err)
+        }
+        return rec, 1
+    }
+    return rec, 0
+}
+
+func printJSON(v any) int {
+    enc := json.NewEncoder(os.Stdout)
+    enc.SetIndent("", "  ")
+    if err := enc.Encode(v); err != nil {
+        fmt.Fprintln(os.Stderr, err)
+        return 1
+    }
+    return 0
+}
+
+func runMode(mode, provider string) string {
+    if provider != "" && provider != mode {
+        return mode + "/" + provider
+    }
+    return mode
+}
+
+func printRun(rec matcher.RunRecord) {
+    fmt.Printf("Run %s (%s)\n", rec.ID, rec.GeneratedAt.Local().Format("2006-01-02 15:04:05"))
+    fmt.Printf("  JD:       %s\n", rec.JDPath)
+    fmt.Printf("  Resumes:  %s (%d found, %d skipped)\n", rec.ResumesDir, rec.Total, len(rec.Skipped))
+    fmt.Printf("  Mode:     %s\n", runMode(rec.Mode, rec.Provider))
+    fmt.Printf("  Profile:  %s (similarity=%.2f must=%.2f nice=%.2f skills=%.2f experience=%.2f)\n",
+        rec.Profile, rec.Weights.Similarity, rec.Weights.Must, rec.Weights.Nice, rec.Weights.Skills, rec.Weights.Experience)
+    if len(rec.Rules) > 0 {
+        fmt.Printf("  Knockout: %s, %d rules, %d excluded\n", rec.KnockoutMode, len(rec.Rules), rec.Excluded)
+    }
+    if rec.Usage != nil {
+        fmt.Printf("  Cost:     $%.4f\n", rec.Usage.CostUSD)
+    }
+    fmt.Printf("  Output:   %s\n\n", rec.OutPath)
+
+    w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+    fmt.Fprintln(w, "RANK\tCANDIDATE\tSCORE\tFILE")
+    for _, r := range rec.Results {
+        name := r.Candidate
+        if r.KnockedOut {
+            name += " (knocked out)"
+        }
+        fmt.Fprintf(w, "%d\t%s\t%.2f\t%s\n", r.Rank, name, r.Score, r.File)
+    }
+    w.Flush()
+}
+
+func printDiff(d matcher.RunDiff) {
+    fmt.Printf("Diff %s -> %s\n", d.From, d.To)
+    if len(d.Settings) == 0 {
+        fmt.Println("\nSettings and inputs: unchanged")
+    } else {
+        fmt.Println("\nSettings and inputs:")
+        for _, s := range d.Settings {
+            fmt.Printf("  %s: %s -> %s\n", s.Name, orNone(s.From), orNone(s.To))
+        }
+    }
+
+    fmt.Println()
+    w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+    fmt.Fprintln(w, "RANK\tMOVE\tCANDIDATE\tSCORE\tDELTA")
+    ranked := append(append([]matcher.CandidateChange{}, d.Changed...), d.Added...)
+    sort.Slice(ranked, func(i, j int) bool { return ranked[i].ToRank < ranked[j].ToRank })
+    for _, c := range ranked {
+        if c.FromRank == 0 {
+            fmt.Fprintf(w, "%d\tnew\t%s\t%.2f\t\n", c.ToRank, c.Candidate, c.ToScore)
+            continue
+        }
+        fmt.Fprintf(w, "%d\t%s\t%s\t%.2f\t%+.2f\n", c.ToRank, rankMove(c.RankDelta()), c.Candidate, c.ToScore, c.ScoreDelta)
+    }
+    for _, c := range d.Removed {
+        fmt.Fprintf(w, "-\tout (was %d)\t%s\t%.2f\t\n", c.FromRank, c.Candidate, c.FromScore)
+    }
+    w.Flush()
+    fmt.Printf("\n%d moved, %d new, %d removed\n", countMoved(d.Changed), len(d.Added), len(d.Removed))
+}
+
+func rankMove(delta int) string {
+    switch {
+    case delta > 0:
+        return fmt.Sprintf("up %d", delta)
+    case delta < 0:
+        return fmt.Sprintf("down %d", -delta)
+    }
+    return "="
+}
+
+func countMoved(changes []matcher.CandidateChange) int {
+    n := 0
+    for _, c := range changes {
+        if c.RankDelta() != 0 {
+            n++
+        }
+    }
+    return n
+}
+
+func orNone(s string) string {
+    if s == "" {
+        return "(none)"
+    }
+    return s
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+    "context"
+    "crypto/rand"
+    "encoding/hex"
+    "errors"
+    "flag"
+    "fmt"
+    "log/slog"
+    "net"
+    "net/http"
+    "os"
+    "os/signal"
+    "time"
+
+    "resume-gpt/internal/matcher"
+    "resume-gpt/internal/server"
+)
+
+// runServe handles "resume_matcher serve": the matcher as a local HTTP API.
+func runServe(args []string) int {
+    fs := flag.NewFlagSet("serve", flag.ContinueOnError)
+    addr := fs.String("addr", envOr("RESUMEGPT_SERVE_ADDR", "127.0.0.1:8787"), "Address to listen on")
+    token := fs.String("token", matcher.Getenv("RESUMEGPT_SERVE_TOKEN"), "Bearer token clients must send (default: RESUMEGPT_SERVE_TOKEN, else a random one is printed)")
+    workDir := fs.String("work-dir", "", "Folder for job uploads and results (default: resumegpt-serve in the temp folder)")
+    maxMB := fs.Int64("max-request-mb", 256, "Largest request body accepted, uploads included, in MB")
+    jobs := fs.Int("jobs", 2, "Jobs run at the same time; the rest wait queued")
+    keep := fs.Int("keep", 100, "Finished jobs kept for polling")
+    if err := fs.Parse(args); err != nil {
+        return 1
+    }
+    if fs.NArg() > 0 {
+        fmt.Fprintln(os.Stderr, "Usage: resume_matcher serve [--addr host:port] [--token T] [--work-dir DIR] [--max-request-mb N] [--jobs N] [--keep N]")
+        return 1
+    }
+
+    printToken := false
+    if *token == "" {
+        *token = randomToken()
+        printToken = true
+    }
+    srv, err := server.New(server.Config{
+        Token:           *token,
+        WorkDir:         *workDir,
+        MaxRequestBytes: *maxMB << 20,
+        MaxConcurrent:   *jobs,
+        KeepJobs:        *keep,
+        Log:             slog.New(slog.NewTextHandler(os.Stderr, nil)),
+    })
+    if err != nil {
+        fmt.Fprintln(os.Stderr, "Failed to start server:", err)
+        return 1
+    }
+    ln, err := net.Listen("tcp", *addr)
+    if err != nil {
+        fmt.Fprintln(os.Stderr, "Failed to start server:", err)
+        return 1
+    }
+
+    fmt.Fprintf(os.Stdout, "Listening on http://%s (jobs in %s)\n", ln.Addr(), srv.WorkDir())
+    if printToken {
+        fmt.Fprintln(os.Stdout, "Token:", *token)
+    }
+    httpServer := &http.Server{Handler: srv, ReadHeaderTimeout: 10 * time.Second}
+
+    // Ctrl-C stops accepting requests, then cancels the jobs still running.
+    ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+    defer stop()
+    go func() {
+        <-ctx.Done()
+        shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+        defer cancel()
+        _ = httpServer.Shutdown(shutdownCtx)
+    }()
+    err = httpServer.Serve(ln)
+    srv.Close()
+    if err != nil && !errors.Is(err, http.ErrServerClosed) {
+        fmt.Fprintln(os.Stderr, "Server failed:", err)
+        return 1
+    }
+    return 0
+}
+
+func envOr(key, def string) string {
+    if v := matcher.Getenv(key); v != "" {
+        return v
+    }
+    return def
+}
+
+func randomToken() string {
+    var b [24]byte
+    _, _ = rand.Read(b[:])
+    return hex.EncodeToString(b[:])
+}
@@ -1,20 +1,59 @@
 const $ = (id) => document.getElementById(id);
 
+const modeSelect = $("mode");
 const jdInput = $("jdPath");
 const resumesInput = $("resumesPath");
+const resumeInput = $("resumePath");
+const jdsInput = $("jdsPath");
 const topNInput = $("topN");
 const outInput = $("outPath");
+const taxonomyInput = $("taxonomyPath");
+const profileSelect = $("profile");
+const weightsInput = $("weights");
+const rulesInput = $("rulesPath");
+const knockoutModeSelect = $("knockoutMode");
+const knockoutFromJDInput = $("knockoutFromJD");
+const logLevelSelect = $("logLevel");
 const statusEl = $("status");
 const totalEl = $("total");
+const totalLabelEl = $("totalLabel");
 const outDisplayEl = $("outDisplay");
+const candidateDisplayEl = $("candidateDisplay");
+const profileDisplayEl = $("profileDisplay");
+const knockoutDisplayEl = $("knockoutDisplay");
+const skippedDisplayEl = $("skippedDisplay");
+const usageDisplayEl = $("usageDisplay");
+const skippedPanel = $("skippedPanel");
+const skippedList = $("skippedList");
 const resultsBody = $("resultsBody");
+const jobsBody = $("jobsBody");
 const resultsSearch = $("resultsSearch");
+const sortBySelect = $("sortBy");
+const minValueInput = $("minValue");
 const runBtn = $("run");
 const pickJDBtn = $("pickJD");
 const pickResumesBtn = $("pickResumes");
+const pickResumeBtn = $("pickResume");
+const pickJDsBtn = $("pickJDs");
 const pickOutBtn = $("pickOut");
+const pickTaxonomyBtn = $("pickTaxonomy");
+const pickRulesBtn = $("pickRules");
+const progressPanel = $("progressPanel");
+const progressBar = $("progressBar");
+const progressText = $("progressText");
+const cancelBtn = $("cancel");
+
+const stageLabels = {
+  extract: "Reading resumes",
+  jd: "Reading job description",
+  embed: "Embedding",
+  score: "Scoring",
+  explain: "Explaining top candidates",
+  write: "Writing results",
+};
 
 let allResults = [];
+let allJobs = [];
 const evalPending = new Set();
 
 function setStatus(text) {
@@ -23,9 +62,16 @@ function setStatus(text) {
 
 function setBusy(isBusy) {
   runBtn.disabled = isBusy;
+  cancelBtn.disabled = !isBusy;
+  progressPanel.hidden = !isBusy;
   pickJDBtn.disabled = isBusy;
   pickResumesBtn.disabled = isBusy;
+  pickResumeBtn.disabled = isBusy;
+  pickJDsBtn.disabled = isBusy;
+  modeSelect.disabled = isBusy;
   pickOutBtn.disabled = isBusy;
+  pickTaxonomyBtn.disabled = isBusy;
+  pickRulesBtn.disabled = isBusy;
 }
 
 function escapeHTML(value) {
@@ -55,6 +101,81 @@ function formatMultiline(value) {
   return escapeHTML(value ?? "").replace(/\n/g, "<br>");
 }
 
+const componentLabels = {
+  similarity: "Similarity",
+  must: "Must-have",
+  nice: "Nice-to-have",
+  skills: "Skills",
+  experience: "Experience",
+};
+
+function componentValue(result, key) {
+  if (key === "score") {
+    return typeof result.score === "number" ? result.score : 0;
+  }
+  const c = result.breakdown?.[key];
+  return c ? c.raw * 100 : 0;
+}
+
+function formatSkillList(list) {
+  return list && list.length ? escapeHTML(list.join(", ")) : "-";
+}
+
+function renderBreakdown(result) {
+  const b = result.breakdown;
+  if (!b) {
+    return "";
+  }
+  const rows = Object.entries(componentLabels)
+    .filter(([key]) => b[key] && b[key].weight > 0)
+    .map(([key, label]) => {
+      const c = b[key];
+      return `<tr>
+        <td>${label}</td>
+        <td>${(c.raw * 100).toFixed(0)}%</td>
+        <td>x ${c.weight.toFixed(2)}</td>
+        <td>= ${c.contribution.toFixed(1)} pts</td>
+      </tr>`;
+    })
+    .join("");
+  return `
+    <details class="breakdown">
+      <summary>Breakdown</summary>
+      <table>${rows}</table>
+      <div class="skills-line">Must matched: ${formatSkillList(b.matchedMust)}</div>
+      <div class="skills-line">Must missing: ${formatSkillList(b.missingMust)}</div>
+      <div class="skills-line">Nice matched: ${formatSkillList(b.matchedNice)}</div>
+      <div class="skills-line">Nice missing: ${formatSkillList(b.missingNice)}</div>
+    </details>
+  `;
+}
+
+function renderKnockout(result) {
+  if (!result.knockedOut) {
+    return "";
+  }
+  const rules = (result.failedRules || []).map(escapeHTML).join("; ");
+  return `<div class="knockout" title="${rules}">Failed: ${rules}</div>`;
+}
+
+function renderWarning(result) {
+  if (!result.warning) {
+    return "";
+  }
+  return `<div class="warning">${escapeHTML(result.warning)}</div>`;
+}
+
+function renderSkipped(skipped) {
+  skippedList.innerHTML = "";
+  skippedDisplayEl.textContent = String(skipped.length);
+  skippedPanel.hidden = skipped.length === 0;
+  for (const s of skipped) {
+    const item = document.createElement("li");
+    item.innerHTML = `<span class="skipped-file">${escapeHTML(s.path)}</span> ${escapeHTML(s.reason)}`;
+    skippedList.appendChild(item);
+  }
+}
+
 function renderEvaluationCell(result) {
   const file = result.file ?? "";
   if (!file) {
@@ -96,13 +217,16 @@ function renderResults(results) {
     const scoreText =
       typeof r.score === "number" ? r.score.toFixed(2) : formatCell(r.score);
     const row = document.createElement("tr");
+    if (r.knockedOut) {
+      row.className = "knocked-out";
+    }
     row.innerHTML = `
       <td>${formatCell(r.rank)}</td>
-      <td>${formatCell(r.candidate)}</td>
+      <td>${formatCell(r.candidate)}${renderKnockout(r)}${renderWarning(r)}</td>
       <td>${scoreText}</td>
       <td>${formatCell(r.strengths)}</td>
       <td>${formatCell(r.weaknesses)}</td>
-      <td>${formatCell(r.explanation)}</td>
+      <td>${formatCell(r.explanation)}${renderBreakdown(r)}</td>
       <td>${renderEvaluationCell(r)}</td>
       <td>
         <button class="file-link" data-open="file" data-file="${escapeHTML(r.file ?? "")}">
@@ -114,17 +238,92 @@ function renderResults(results) {
   }
 }
 
+function renderSkillMatch(matched, missing) {
+  const have = (matched || []).length;
+  const total = have + (missing || []).length;
+  if (total === 0) {
+    return "-";
+  }
+  return `
+    <div>${have}/${total}</div>
+    <div class="skill-match">Matched: ${formatSkillList(matched)}</div>
+    <div class="skill-match">Missing: ${formatSkillList(missing)}</div>
+  `;
+}
+
+function renderJobs(jobs) {
+  jobsBody.innerHTML = "";
+  if (!jobs || jobs.length === 0) {
+    const row = document.createElement("tr");
+    const cell = document.createElement("td");
+    cell.colSpan = 7;
+    cell.className = "empty";
+    cell.textContent = "No results to display";
+    row.appendChild(cell);
+    jobsBody.appendChild(row);
+    return;
+  }
+
+  for (const j of jobs) {
+    const b = j.breakdown || {};
+    const scoreText =
+      typeof j.score === "number" ? j.score.toFixed(2) : formatCell(j.score);
+    const row = document.createElement("tr");
+    if (j.knockedOut) {
+      row.className = "knocked-out";
+    }
+    row.innerHTML = `
+      <td>${formatCell(j.rank)}</td>
+      <td>${formatCell(j.jdInfo?.role_title || j.job)}${renderKnockout(j)}${renderWarning(j)}</td>
+      <td>${scoreText}</td>
+      <td>${renderSkillMatch(b.matchedMust, b.missingMust)}</td>
+      <td>${renderSkillMatch(b.matchedNice, b.missingNice)}</td>
+      <td>${formatCell(j.explanation)}${renderBreakdown(j)}</td>
+      <td>
+        <button class="file-link" data-open="file" data-file="${escapeHTML(j.jdPath ?? "")}">
+          ${formatCell(j.job)}
+        </button>
+      </td>
+    `;
+    jobsBody.appendChild(row);
+  }
+}
+
 function applySearchFilter() {
   const query = resultsSearch.value.trim().toLowerCase();
-  if (!query) {
-    renderResults(allResults);
-    return;
+  const key = sortBySelect.value;
+  const minValue = parseFloat(minValueInput.value);
+  const jobsMode = modeSelect.value === "jobs";
+
+  let filtered = jobsMode ? allJobs : allResults;
+  if (query) {
+    filtered = filtered.filter((r) =>
+      String((jobsMode ? r.job : r.candidate) ?? "").toLowerCase().includes(query)
+    );
+  }
+  if (!Number.isNaN(minValue)) {
+    filtered = filtered.filter((r) => componentValue(r, key) >= minValue);
+  }
+  if (key !== "score") {
+    filtered = [...filtered].sort(
+      (a, b) => componentValue(b, key) - componentValue(a, key)
+    );
   }
+  if (jobsMode) {
+    renderJobs(filtered);
+  } else {
+    renderResults(filtered);
+  }
+}
 
-  const filtered = allResults.filter((r) =>
-    String(r.candidate ?? "").toLowerCase().includes(query)
-  );
-  renderResults(filtered);
+function setMode() {
+  const mode = modeSelect.value;
+  for (const el of document.querySelectorAll("[data-mode]")) {
+    el.hidden = el.dataset.mode !== mode;
+  }
+  totalLabelEl.textContent = mode === "jobs" ? "Total jobs scored" : "Total resumes scored";
+  resultsSearch.placeholder = mode === "jobs" ? "Search job name" : "Search candidate name";
+  applySearchFilter();
 }
 
 async function pickJD() {
@@ -149,6 +348,28 @@ async function pickResumes() {
   }
 }
 
+async function pickResume() {
+  try {
+    const path = await window.go.main.App.SelectResumeFile();
+    if (path) {
+      resumeInput.value = path;
+    }
+  } catch (err) {
+    setStatus(`Error: ${err}`);
+  }
+}
+
+async function pickJDs() {
+  try {
+    const path = await window.go.main.App.SelectJDsFolder();
+    if (path) {
+      jdsInput.value = path;
+    }
+  } catch (err) {
+    setStatus(`Error: ${err}`);
+  }
+}
+
 async function pickOutput() {
   try {
     const path = await window.go.main.App.SelectOutputFile();
@@ -160,10 +381,106 @@ async function pickOutput() {
   }
 }
 
+async function pickTaxonomy() {
+  try {
+    const path = await window.go.main.App.SelectTaxonomyFile();
+    if (path) {
+      taxonomyInput.value = path;
+    }
+  } catch (err) {
+    setStatus(`Error: ${err}`);
+  }
+}
+
+async function pickRules() {
+  try {
+    const path = await window.go.main.App.SelectRulesFile();
+    if (path) {
+      rulesInput.value = path;
+    }
+  } catch (err) {
+    setStatus(`Error: ${err}`);
+  }
+}
+
+async function loadProfiles() {
+  try {
+    const profiles = await window.go.main.App.ListProfiles();
+    if (!profiles || profiles.length === 0) {
+      return;
+    }
+    profileSelect.innerHTML = "";
+    for (const p of profiles) {
+      const option = document.createElement("option");
+      option.value = p.name;
+      option.textContent = p.name;
+      option.title = p.description ?? "";
+      profileSelect.appendChild(option);
+    }
+    profileSelect.value = "default";
+  } catch (err) {
+    setStatus(`Failed to load profiles: ${err}`);
+  }
+}
+
+function formatWeights(weights) {
+  if (!weights) {
+    return "";
+  }
+  return ["similarity", "must", "nice", "skills", "experience"]
+    .filter((k) => weights[k] > 0)
+    .map((k) => `${k} ${weights[k].toFixed(2)}`)
+    .join(", ");
+}
+
+function formatKnockout(output) {
+  const rules = output.rules || [];
+  if (rules.length === 0) {
+    return "No rules";
+  }
+  const failed = (output.results || output.jobs || []).filter((r) => r.knockedOut).length;
+  if (output.knockoutMode === "exclude") {
+    return `${output.excluded ?? 0} excluded (${rules.length} rules)`;
+  }
+  return `${failed} ranked last (${rules.length} rules)`;
+}
+
+function formatUsage(output) {
+  const usage = output.usage;
+  if (!usage) {
+    return "-";
+  }
+  const tokens = usage.promptTokens + usage.completionTokens + usage.embeddingTokens;
+  let text = `$${usage.costUsd.toFixed(4)} (${tokens.toLocaleString()} tokens)`;
+  if (usage.explainSkipped) {
+    text += `, budget reached: ${usage.explainSkipped} explanations skipped`;
+  }
+  return text;
+}
+
+function showProgress(progress) {
+  const label = stageLabels[progress.stage] || progress.stage;
+  progressBar.max = Math.max(progress.total, 1);
+  progressBar.value = progress.done;
+  const file = progress.file ? ` ${progress.file.split(/[\\/]/).pop()}` : "";
+  progressText.textContent = `${label} ${progress.done}/${progress.total}${file}`;
+}
+
+async function cancelMatcher() {
+  cancelBtn.disabled = true;
+  setStatus("Canceling...");
+  await window.go.main.App.CancelMatch();
+}
+
 async function runMatcher() {
+  if (modeSelect.value === "jobs") {
+    await runJobMatch();
+    return;
+  }
   const jdPath = jdInput.value.trim();
   const resumesPath = resumesInput.value.trim();
   const outPath = outInput.value.trim();
+  const taxonomyPath = taxonomyInput.value.trim();
 
   let topN = parseInt(topNInput.value, 10);
   if (Number.isNaN(topN) || topN < 0) {
@@ -177,19 +494,95 @@ async function runMatcher() {
 
   setBusy(true);
   setStatus("Running...");
+  progressBar.value = 0;
+  progressText.textContent = "";
 
   try {
-    const output = await window.go.main.App.RunMatch(jdPath, resumesPath, topN, outPath);
+    const output = await window.go.main.App.RunMatch({
+      jdPath,
+      resumesDir: resumesPath,
+      topN,
+      outPath,
+      taxonomyPath,
+      profile: profileSelect.value,
+      weights: weightsInput.value.trim(),
+      rulesPath: rulesInput.value.trim(),
+      knockoutFromJD: knockoutFromJDInput.checked,
+      knockoutMode: knockoutModeSelect.value,
+      logLevel: logLevelSelect.value,
+    });
     allResults = output.results || [];
     applySearchFilter();
     totalEl.textContent = output.total ?? "-";
     outDisplayEl.textContent = output.outPath || outPath || "-";
+    profileDisplayEl.textContent = output.profile
+      ? `${output.profile} (${formatWeights(output.weights)})`
+      : "-";
+    knockoutDisplayEl.textContent = formatKnockout(output);
+    usageDisplayEl.textContent = formatUsage(output);
+    renderSkipped(output.skipped || []);
     if (output.outPath) {
       outInput.value = output.outPath;
     }
-    setStatus("Completed");
+    setStatus(
+      output.skipped && output.skipped.length
+        ? `Completed (${output.skipped.length} files skipped)`
+        : "Completed"
+    );
+  } catch (err) {
+    setStatus(String(err).includes("context canceled") ? "Canceled" : `Failed: ${err}`);
+  } finally {
+    setBusy(false);
+  }
+}
+
+async function runJobMatch() {
+  const resumePath = resumeInput.value.trim();
+  const jdsDir = jdsInput.value.trim();
+
+  let topN = parseInt(topNInput.value, 10);
+  if (Number.isNaN(topN) || topN < 0) {
+    topN = 0;
+  }
+
+  if (!resumePath || !jdsDir) {
+    setStatus("Please select a resume and a job descriptions folder");
+    return;
+  }
+
+  setBusy(true);
+  setStatus("Running...");
+  progressBar.value = 0;
+  progressText.textContent = "";
+
+  try {
+    const output = await window.go.main.App.MatchJobs({
+      resumePath,
+      jdsDir,
+      topN,
+      taxonomyPath: taxonomyInput.value.trim(),
+      profile: profileSelect.value,
+      weights: weightsInput.value.trim(),
+      rulesPath: rulesInput.value.trim(),
+      knockoutFromJD: knockoutFromJDInput.checked,
+      knockoutMode: knockoutModeSelect.value,
+    });
+    allJobs = output.jobs || [];
+    applySearchFilter();
+    totalEl.textContent = output.total ?? "-";
+    candidateDisplayEl.textContent = output.candidate || "-";
+    const weights = allJobs.length ? formatWeights(allJobs[0].weights) : "";
+    profileDisplayEl.textContent = output.profile
+      ? `${output.profile}${weights ? ` (${weights})` : ""}`
+      : "-";
+    knockoutDisplayEl.textContent =
+      output.knockoutMode === "exclude" && output.excluded
+        ? `${output.excluded} jobs excluded`
+        : `${allJobs.filter((j) => j.knockedOut).length} jobs ranked last`;
+    usageDisplayEl.textContent = formatUsage(output);
+    setStatus(output.warning ? `Completed (${output.warning})` : "Completed");
   } catch (err) {
-    setStatus(`Failed: ${err}`);
+    setStatus(String(err).includes("context canceled") ? "Canceled" : `Failed: ${err}`);
   } finally {
     setBusy(false);
   }
@@ -227,10 +620,30 @@ async function evaluateCandidate(filePath) {
 }
 
 resultsSearch.addEventListener("input", applySearchFilter);
+sortBySelect.addEventListener("change", applySearchFilter);
+minValueInput.addEventListener("input", applySearchFilter);
 pickJDBtn.addEventListener("click", pickJD);
 pickResumesBtn.addEventListener("click", pickResumes);
+pickResumeBtn.addEventListener("click", pickResume);
+pickJDsBtn.addEventListener("click", pickJDs);
+modeSelect.addEventListener("change", setMode);
 pickOutBtn.addEventListener("click", pickOutput);
+pickTaxonomyBtn.addEventListener("click", pickTaxonomy);
+pickRulesBtn.addEventListener("click", pickRules);
 runBtn.addEventListener("click", runMatcher);
+cancelBtn.addEventListener("click", cancelMatcher);
+window.runtime.EventsOn("match:progress", showProgress);
+loadProfiles();
+jobsBody.addEventListener("click", (event) => {
+  const openBtn = event.target.closest("button[data-open='file']");
+  const file = openBtn?.getAttribute("data-file");
+  if (!file) {
+    return;
+  }
+  window.go.main.App.OpenResumeFile(file).catch((err) => {
+    setStatus(`Failed to open file: ${err}`);
+  });
+});
 resultsBody.addEventListener("click", (event) => {
   const btn = event.target.closest("button[data-eval]");
   if (!btn) {
@@ -1,15 +1,30 @@
 // Cynhyrchwyd y ffeil hon yn awtomatig. PEIDIWCH Â MODIWL
 // This file is automatically generated. DO NOT EDIT
 import {matcher} from '../models';
+import {main} from '../models';
+
+export function CancelMatch():Promise<void>;
 
 export function EvaluateCandidate(arg1:string,arg2:string):Promise<matcher.ResumeAnalysis>;
 
+export function ListProfiles():Promise<Array<matcher.ScoringProfile>>;
+
+export function MatchJobs(arg1:main.JobMatchOptions):Promise<matcher.JobsOutput>;
+
 export function OpenResumeFile(arg1:string):Promise<void>;
 
-export function RunMatch(arg1:string,arg2:string,arg3:number,arg4:string):Promise<matcher.Output>;
+export function RunMatch(arg1:main.MatchOptions):Promise<matcher.Output>;
 
 export function SelectJDFile():Promise<string>;
 
+export function SelectJDsFolder():Promise<string>;
+
 export function SelectOutputFile():Promise<string>;
 
 export function SelectResumesFolder():Promise<string>;
+
+export function SelectResumeFile():Promise<string>;
+
+export function SelectRulesFile():Promise<string>;
+
+export function SelectTaxonomyFile():Promise<string>;
@@ -2,22 +2,38 @@
 // Cynhyrchwyd y ffeil hon yn awtomatig. PEIDIWCH Â MODIWL
 // This file is automatically generated. DO NOT EDIT
 
+export function CancelMatch() {
+  return window['go']['main']['App']['CancelMatch']();
+}
+
 export function EvaluateCandidate(arg1, arg2) {
   return window['go']['main']['App']['EvaluateCandidate'](arg1, arg2);
 }
 
+export function ListProfiles() {
+  return window['go']['main']['App']['ListProfiles']();
+}
+
+export function MatchJobs(arg1) {
+  return window['go']['main']['App']['MatchJobs'](arg1);
+}
+
 export function OpenResumeFile(arg1) {
   return window['go']['main']['App']['OpenResumeFile'](arg1);
 }
 
-export function RunMatch(arg1, arg2, arg3, arg4) {
-  return window['go']['main']['App']['RunMatch'](arg1, arg2, arg3, arg4);
+export function RunMatch(arg1) {
+  return window['go']['main']['App']['RunMatch'](arg1);
 }
 
 export function SelectJDFile() {
   return window['go']['main']['App']['SelectJDFile']();
 }
 
+export function SelectJDsFolder() {
+  return window['go']['main']['App']['SelectJDsFolder']();
+}
+
 export function SelectOutputFile() {
   return window['go']['main']['App']['SelectOutputFile']();
 }
@@ -25,3 +41,15 @@ export function SelectOutputFile() {
 export function SelectResumesFolder() {
   return window['go']['main']['App']['SelectResumesFolder']();
 }
+
+export function SelectResumeFile() {
+  return window['go']['main']['App']['SelectResumeFile']();
+}
+
+export function SelectRulesFile() {
+  return window['go']['main']['App']['SelectRulesFile']();
+}
+
+export function SelectTaxonomyFile() {
+  return window['go']['main']['App']['SelectTaxonomyFile']();
+}
@@ -1,3 +1,68 @@
+export namespace main {
+	
+	export class JobMatchOptions {
+	    resumePath: string;
+	    jdsDir: string;
+	    topN: number;
+	    taxonomyPath: string;
+	    profile: string;
+	    weights: string;
+	    rulesPath: string;
+	    knockoutFromJD: boolean;
+	    knockoutMode: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new JobMatchOptions(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.resumePath = source["resumePath"];
+	        this.jdsDir = source["jdsDir"];
+	        this.topN = source["topN"];
+	        this.taxonomyPath = source["taxonomyPath"];
+	        this.profile = source["profile"];
+	        this.weights = source["weights"];
+	        this.rulesPath = source["rulesPath"];
+	        this.knockoutFromJD = source["knockoutFromJD"];
+	        this.knockoutMode = source["knockoutMode"];
+	    }
+	}
+	export class MatchOptions {
+	    jdPath: string;
+	    resumesDir: string;
+	    topN: number;
+	    outPath: string;
+	    taxonomyPath: string;
+	    profile: string;
+	    weights: string;
+	    rulesPath: string;
+	    knockoutFromJD: boolean;
+	    knockoutMode: string;
+	    logLevel: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new MatchOptions(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.jdPath = source["jdPath"];
+	        this.resumesDir = source["resumesDir"];
+	        this.topN = source["topN"];
+	        this.outPath = source["outPath"];
+	        this.taxonomyPath = source["taxonomyPath"];
+	        this.profile = source["profile"];
+	        this.weights = source["weights"];
+	        this.rulesPath = source["rulesPath"];
+	        this.knockoutFromJD = source["knockoutFromJD"];
+	        this.knockoutMode = source["knockoutMode"];
+	        this.logLevel = source["logLevel"];
+	    }
+	}
+
+}
+
 export namespace matcher {
 	
 	export class JDExtract {
@@ -28,12 +93,89 @@ export namespace matcher {
 	        this.responsibilities = source["responsibilities"];
 	    }
 	}
+	export class KnockoutRule {
+	    name: string;
+	    type: string;
+	    skills?: string[];
+	    years?: number;
+	    level?: string;
+	    values?: string[];
+	
+	    static createFrom(source: any = {}) {
+	        return new KnockoutRule(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.type = source["type"];
+	        this.skills = source["skills"];
+	        this.years = source["years"];
+	        this.level = source["level"];
+	        this.values = source["values"];
+	    }
+	}
+	export class EmbeddingCacheStats {
+	    hits: number;
+	    misses: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new EmbeddingCacheStats(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.hits = source["hits"];
+	        this.misses = source["misses"];
+	    }
+	}
+	export class Usage {
+	    requests: number;
+	    promptTokens: number;
+	    completionTokens: number;
+	    embeddingTokens: number;
+	    costUsd: number;
+	    unpriced?: string[];
+	    budgetUsd?: number;
+	    explainSkipped?: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new Usage(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.requests = source["requests"];
+	        this.promptTokens = source["promptTokens"];
+	        this.completionTokens = source["completionTokens"];
+	        this.embeddingTokens = source["embeddingTokens"];
+	        this.costUsd = source["costUsd"];
+	        this.unpriced = source["unpriced"];
+	        this.budgetUsd = source["budgetUsd"];
+	        this.explainSkipped = source["explainSkipped"];
+	    }
+	}
+	export class SkippedFile {
+	    path: string;
+	    reason: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new SkippedFile(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.path = source["path"];
+	        this.reason = source["reason"];
+	    }
+	}
 	export class ResumeExtract {
 	    skills: string[];
 	    years_experience: number;
 	    education: string[];
 	    certifications: string[];
 	    titles: string[];
+	    skill_last_used?: Record<string, number>;
 	
 	    static createFrom(source: any = {}) {
 	        return new ResumeExtract(source);
@@ -46,7 +188,74 @@ export namespace matcher {
 	        this.education = source["education"];
 	        this.certifications = source["certifications"];
 	        this.titles = source["titles"];
+	        this.skill_last_used = source["skill_last_used"];
+	    }
+	}
+	export class ScoreComponent {
+	    raw: number;
+	    weight: number;
+	    contribution: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new ScoreComponent(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.raw = source["raw"];
+	        this.weight = source["weight"];
+	        this.contribution = source["contribution"];
+	    }
+	}
+	export class ScoreBreakdown {
+	    similarity: ScoreComponent;
+	    must: ScoreComponent;
+	    nice: ScoreComponent;
+	    skills: ScoreComponent;
+	    experience: ScoreComponent;
+	    matchedMust: string[];
+	    missingMust: string[];
+	    matchedNice: string[];
+	    missingNice: string[];
+	    matchedSkills: string[];
+	    missingSkills: string[];
+	
+	    static createFrom(source: any = {}) {
+	        return new ScoreBreakdown(source);
 	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.similarity = this.convertValues(source["similarity"], ScoreComponent);
+	        this.must = this.convertValues(source["must"], ScoreComponent);
+	        this.nice = this.convertValues(source["nice"], ScoreComponent);
+	        this.skills = this.convertValues(source["skills"], ScoreComponent);
+	        this.experience = this.convertValues(source["experience"], ScoreComponent);
+	        this.matchedMust = source["matchedMust"];
+	        this.missingMust = source["missingMust"];
+	        this.matchedNice = source["matchedNice"];
+	        this.missingNice = source["missingNice"];
+	        this.matchedSkills = source["matchedSkills"];
+	        this.missingSkills = source["missingSkills"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
 	}
 	export class Result {
 	    rank: number;
@@ -56,6 +265,10 @@ export namespace matcher {
 	    weaknesses: string;
 	    explanation: string;
 	    file: string;
+	    breakdown: ScoreBreakdown;
+	    knockedOut: boolean;
+	    failedRules?: string[];
+	    warning?: string;
 	    extracted?: ResumeExtract;
 	
 	    static createFrom(source: any = {}) {
@@ -71,6 +284,10 @@ export namespace matcher {
 	        this.weaknesses = source["weaknesses"];
 	        this.explanation = source["explanation"];
 	        this.file = source["file"];
+	        this.breakdown = this.convertValues(source["breakdown"], ScoreBreakdown);
+	        this.knockedOut = source["knockedOut"];
+	        this.failedRules = source["failedRules"];
+	        this.warning = source["warning"];
 	        this.extracted = this.convertValues(source["extracted"], ResumeExtract);
 	    }
 	
@@ -92,11 +309,46 @@ export namespace matcher {
 		    return a;
 		}
 	}
+	export class Weights {
+	    similarity: number;
+	    must: number;
+	    nice: number;
+	    skills: number;
+	    experience: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new Weights(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.similarity = source["similarity"];
+	        this.must = source["must"];
+	        this.nice = source["nice"];
+	        this.skills = source["skills"];
+	        this.experience = source["experience"];
+	    }
+	}
 	export class Output {
 	    results: Result[];
 	    outPath: string;
+	    files?: {[key: string]: string};
+	    mode: string;
 	    total: number;
 	    jdInfo?: JDExtract;
+	    taxonomy?: string;
+	    profile: string;
+	    weights: Weights;
+	    knockoutMode: string;
+	    rules?: KnockoutRule[];
+	    excluded: number;
+	    skipped: SkippedFile[];
+	    flagged: SkippedFile[];
+	    provider?: string;
+	    embeddingCache?: EmbeddingCacheStats;
+	    usage?: Usage;
+	    runId?: string;
+	    logPath?: string;
 	
 	    static createFrom(source: any = {}) {
 	        return new Output(source);
@@ -106,8 +358,131 @@ export namespace matcher {
 	        if ('string' === typeof source) source = JSON.parse(source);
 	        this.results = this.convertValues(source["results"], Result);
 	        this.outPath = source["outPath"];
+	        this.files = source["files"];
+	        this.mode = source["mode"];
 	        this.total = source["total"];
 	        this.jdInfo = this.convertValues(source["jdInfo"], JDExtract);
+	        this.taxonomy = source["taxonomy"];
+	        this.profile = source["profile"];
+	        this.weights = this.convertValues(source["weights"], Weights);
+	        this.knockoutMode = source["knockoutMode"];
+	        this.rules = this.convertValues(source["rules"], KnockoutRule);
+	        this.excluded = source["excluded"];
+	        this.skipped = this.convertValues(source["skipped"], SkippedFile);
+	        this.flagged = this.convertValues(source["flagged"], SkippedFile);
+	        this.provider = source["provider"];
+	        this.embeddingCache = this.convertValues(source["embeddingCache"], EmbeddingCacheStats);
+	        this.usage = this.convertValues(source["usage"], Usage);
+	        this.runId = source["runId"];
+	        this.logPath = source["logPath"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class JobMatch {
+	    rank: number;
+	    job: string;
+	    jdPath: string;
+	    score: number;
+	    strengths: string;
+	    weaknesses: string;
+	    explanation: string;
+	    breakdown: ScoreBreakdown;
+	    knockedOut: boolean;
+	    failedRules?: string[];
+	    warning?: string;
+	    jdInfo?: JDExtract;
+	    weights: Weights;
+	
+	    static createFrom(source: any = {}) {
+	        return new JobMatch(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.rank = source["rank"];
+	        this.job = source["job"];
+	        this.jdPath = source["jdPath"];
+	        this.score = source["score"];
+	        this.strengths = source["strengths"];
+	        this.weaknesses = source["weaknesses"];
+	        this.explanation = source["explanation"];
+	        this.breakdown = this.convertValues(source["breakdown"], ScoreBreakdown);
+	        this.knockedOut = source["knockedOut"];
+	        this.failedRules = source["failedRules"];
+	        this.warning = source["warning"];
+	        this.jdInfo = this.convertValues(source["jdInfo"], JDExtract);
+	        this.weights = this.convertValues(source["weights"], Weights);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class JobsOutput {
+	    candidate: string;
+	    resumePath: string;
+	    jobs: JobMatch[];
+	    mode: string;
+	    provider?: string;
+	    total: number;
+	    excluded: number;
+	    profile: string;
+	    taxonomy?: string;
+	    knockoutMode: string;
+	    warning?: string;
+	    embeddingCache?: EmbeddingCacheStats;
+	    usage?: Usage;
+	
+	    static createFrom(source: any = {}) {
+	        return new JobsOutput(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.candidate = source["candidate"];
+	        this.resumePath = source["resumePath"];
+	        this.jobs = this.convertValues(source["jobs"], JobMatch);
+	        this.mode = source["mode"];
+	        this.provider = source["provider"];
+	        this.total = source["total"];
+	        this.excluded = source["excluded"];
+	        this.profile = source["profile"];
+	        this.taxonomy = source["taxonomy"];
+	        this.knockoutMode = source["knockoutMode"];
+	        this.warning = source["warning"];
+	        this.embeddingCache = this.convertValues(source["embeddingCache"], EmbeddingCacheStats);
+	        this.usage = this.convertValues(source["usage"], Usage);
 	    }
 	
 		convertValues(a: any, classs: any, asMap: boolean = false): any {
@@ -156,5 +531,42 @@ export namespace matcher {
 	    }
 	}
 
+	export class ScoringProfile {
+	    name: string;
+	    description: string;
+	    weights: Weights;
+	    noMust?: Weights;
+	
+	    static createFrom(source: any = {}) {
+	        return new ScoringProfile(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.description = source["description"];
+	        this.weights = this.convertValues(source["weights"], Weights);
+	        this.noMust = this.convertValues(source["noMust"], Weights);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+
 }
 
@@ -0,0 +1,473 @@
+package matcher
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultBestFit is how many jobs a candidate's best-fit list holds unless
+// BatchInput.BestFit says otherwise.
+const DefaultBestFit = 3
+
+// BatchInput scores one resumes folder against several JDs. Input.JDPath and
+// Input.ResultsWorkbook are ignored; Input.OutPath is the matrix file
+// (outputs/matrix.csv by default) and each JD's top N is written to
+// jobs/<jd name>/ next to it.
+type BatchInput struct {
+	Input
+	// JDPaths lists JD files and folders of JDs.
+	JDPaths []string
+	// BestFit is the length of each candidate's best-fit list; 0 uses
+	// DefaultBestFit.
+	BestFit int
+}
+
+// BatchOutput is the candidate × job matrix of a batch run.
+type BatchOutput struct {
+	Jobs       []BatchJob     `json:"jobs"`
+	Candidates []CandidateFit `json:"candidates"`
+	OutPath    string         `json:"outPath"`
+	// Files maps every written matrix format, and "bestFit", to its path.
+	Files          map[string]string    `json:"files,omitempty"`
+	Mode           string               `json:"mode"`
+	Provider       string               `json:"provider,omitempty"`
+	Total          int                  `json:"total"`
+	Profile        string               `json:"profile"`
+	Taxonomy       string               `json:"taxonomy,omitempty"`
+	KnockoutMode   string               `json:"knockoutMode"`
+	Skipped        []SkippedFile        `json:"skipped"`
+	Flagged        []SkippedFile        `json:"flagged"`
+	EmbeddingCache *EmbeddingCacheStats `json:"embeddingCache,omitempty"`
+	Usage          *Usage               `json:"usage,omitempty"`
+	LogPath        string               `json:"logPath,omitempty"`
+}
+
+// BatchJob is one JD of a batch run and where its top N was written.
+type BatchJob struct {
+	// Name is the JD file name without extension, made unique within the
+	// batch; it heads the job's matrix column.
+	Name     string            `json:"name"`
+	JDPath   string            `json:"jdPath"`
+	JDInfo   *JDExtract        `json:"jdInfo,omitempty"`
+	Weights  Weights           `json:"weights"`
+	Rules    []KnockoutRule    `json:"rules,omitempty"`
+	Ranked   int               `json:"ranked"`
+	Excluded int               `json:"excluded"`
+	OutPath  string            `json:"outPath"`
+	Files    map[string]string `json:"files,omitempty"`
+}
+
+// CandidateFit is one row of the matrix: the candidate's score for every job,
+// in BatchOutput.Jobs order, and the jobs that fit them best.
+type CandidateFit struct {
+	Candidate string     `json:"candidate"`
+	File      string     `json:"file"`
+	Scores    []JobScore `json:"scores"`
+	BestFit   []JobScore `json:"bestFit"`
+}
+
+// JobScore is a candidate's result for one job. Rank is the candidate's place
+// in that job's ranking; Excluded candidates failed a knockout rule in
+// exclude mode and have no score.
+type JobScore struct {
+	Job         string   `json:"job"`
+	Score       float64  `json:"score"`
+	Rank        int      `json:"rank"`
+	KnockedOut  bool     `json:"knockedOut"`
+	Excluded    bool     `json:"excluded,omitempty"`
+	FailedRules []string `json:"failedRules,omitempty"`
+}
+
+// RunBatch scores the resumes of input against every JD in one pass: resumes
+// are read once and, in OpenAI mode, embedded once. Canceling ctx stops the
+// run and returns ctx.Err() without writing the matrix.
+func RunBatch(ctx context.Context, input BatchInput) (BatchOutput, error) {
+	return runBatchInternal(ctx, input, false)
+}
+
+// RunBatchHeuristic is RunBatch with the heuristic ranking (no OpenAI).
+func RunBatchHeuristic(ctx context.Context, input BatchInput) (BatchOutput, error) {
+	return runBatchInternal(ctx, input, true)
+}
+
+func runBatchInternal(ctx context.Context, input BatchInput, forceHeuristic bool) (BatchOutput, error) {
+	LoadDotEnv()
+	var err error
+	input.Input, err = withEnvDefaults(input.Input)
+	if err != nil {
+		return BatchOutput{}, err
+	}
+	if err := checkLogLevel(input.Input); err != nil {
+		return BatchOutput{}, err
+	}
+	matrixPath := matrixPath(input)
+	log, logPath, closeLog := openRunLog(input.Input, matrixPath, newRunID())
+	defer closeLog()
+	log.Info("batch started",
+		"jds", input.JDPaths,
+		"resumes", input.ResumesDir,
+		"top_n", input.TopN,
+		"out", matrixPath,
+		"profile", input.Profile,
+		"heuristic_only", forceHeuristic,
+	)
+
+	started := time.Now()
+	out, err := batchStages(withLogger(ctx, log), input, forceHeuristic, matrixPath)
+	took := durationMS(time.Since(started))
+	switch {
+	case err == nil:
+		out.LogPath = logPath
+		attrs := []any{
+			"duration_ms", took,
+			"mode", out.Mode,
+			"jobs", len(out.Jobs),
+			"candidates", len(out.Candidates),
+			"skipped", len(out.Skipped),
+			"flagged", len(out.Flagged),
+			"files", out.Files,
+		}
+		if out.Provider != "" {
+			attrs = append(attrs, "provider", out.Provider)
+		}
+		if out.EmbeddingCache != nil {
+			attrs = append(attrs, "embedding_cache", out.EmbeddingCache)
+		}
+		if out.Usage != nil {
+			attrs = append(attrs, "usage", out.Usage)
+		}
+		log.Info("batch finished", attrs...)
+	case errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded):
+		log.Warn("batch canceled", "duration_ms", took, "error", err)
+	default:
+		log.Error("batch failed", "duration_ms", took, "error", err)
+	}
+	return out, err
+}
+
+// matrixPath is Input.OutPath, outputs/matrix.csv by default.
+func matrixPath(input BatchInput) string {
+	if outPath := strings.TrimSpace(input.OutPath); outPath != "" {
+		return outPath
+	}
+	return filepath.Join("outputs", "matrix.csv")
+}
+
+func batchStages(ctx context.Context, input BatchInput, forceHeuristic bool, matrixPath string) (BatchOutput, error) {
+	jdPaths, err := listJDFiles(input.JDPaths)
+	if err != nil {
+		return BatchOutput{}, err
+	}
+	if strings.TrimSpace(input.ResumesDir) == "" || !dirExists(input.ResumesDir) {
+		return BatchOutput{}, ErrMissingResumes
+	}
+
+	tax, err := loadTaxonomy(input.TaxonomyPath)
+	if err != nil {
+		return BatchOutput{}, err
+	}
+	profile, err := selectProfile(input.ProfilesPath, input.Profile, input.Weights)
+	if err != nil {
+		return BatchOutput{}, err
+	}
+	rules, err := loadRules(input.RulesPath, input.KnockoutFromJD, input.KnockoutMode)
+	if err != nil {
+		return BatchOutput{}, err
+	}
+	formats, err := outputFormats(input.Formats, matrixPath)
+	if err != nil {
+		return BatchOutput{}, err
+	}
+	if _, err := normalizeReport(input.Report); err != nil {
+		return BatchOutput{}, err
+	}
+
+	jdRaws := make([]string, len(jdPaths))
+	for i, path := range jdPaths {
+		jdRaws[i], _, err = extractTextCached(path)
+		if err != nil {
+			return BatchOutput{}, fmt.Errorf("%w: %s: %v", ErrReadJD, path, err)
+		}
+	}
+
+	resumeFiles, err := listResumeFiles(input.ResumesDir)
+	if err != nil {
+		return BatchOutput{}, fmt.Errorf("%w: %v", ErrListResumes, err)
+	}
+	if len(resumeFiles) == 0 {
+		return BatchOutput{}, ErrNoResumes
+	}
+	totalResumes := len(resumeFiles)
+	prog := newProgressReporter(input.Progress, logFrom(ctx))
+	defer prog.finish()
+
+	resumeDocs, skipped, flagged, err := loadResumes(ctx, resumeFiles, workerCount(input.Workers), prog)
+	if err != nil {
+		return BatchOutput{}, err
+	}
+	if len(resumeDocs) == 0 {
+		return BatchOutput{}, fmt.Errorf("%w: none of the %d files could be read (%s: %s)", ErrNoResumes, totalResumes, skipped[0].Path, skipped[0].Reason)
+	}
+
+	client, err := selectClient(ctx, forceHeuristic)
+	if err != nil {
+		return BatchOutput{}, err
+	}
+	// Every JD and resume is embedded in one go, so each resume is sent to
+	// the provider once however many JDs there are.
+	var jdVecs, resumeVecs [][]float64
+	if client != nil {
+		texts := make([]string, 0, len(jdRaws)+len(resumeDocs))
+		for _, raw := range jdRaws {
+			texts = append(texts, redactForProvider(raw))
+		}
+		for _, doc := range resumeDocs {
+			texts = append(texts, doc.Redacted)
+		}
+		vecs, err := fitAndEmbed(ctx, client, tax, texts, prog)
+		if err != nil {
+			return BatchOutput{}, err
+		}
+		jdVecs, resumeVecs = vecs[:len(jdRaws)], vecs[len(jdRaws):]
+	}
+
+	names := jobNames(jdPaths)
+	jobsDir := filepath.Join(filepath.Dir(matrixPath), "jobs")
+	batch := BatchOutput{
+		Jobs:         make([]BatchJob, len(jdPaths)),
+		Mode:         "heuristic",
+		Total:        totalResumes,
+		Profile:      profile.Name,
+		Taxonomy:     tax.version,
+		KnockoutMode: rules.mode,
+		Skipped:      skipped,
+		Flagged:      flagged,
+	}
+	outputs := make([]Output, len(jdPaths))
+	for j, jdPath := range jdPaths {
+		jobInput := input.Input
+		jobInput.JDPath = jdPath
+		jobInput.OutPath = filepath.Join(jobsDir, names[j], "results."+formats[0])
+		jobInput.Formats = formats
+		jobInput.ResultsWorkbook = ""
+
+		var out Output
+		if client != nil {
+			embeddings := append([][]float64{jdVecs[j]}, resumeVecs...)
+			out, err = runOpenAI(ctx, prog, jobInput, tax, profile, rules, jdRaws[j], resumeDocs, totalResumes, client, embeddings)
+		} else {
+			out, err = runHeuristic(ctx, prog, jobInput, tax, profile, rules, jdRaws[j], resumeDocs, totalResumes)
+		}
+		if err != nil {
+			return BatchOutput{}, err
+		}
+		outputs[j] = out
+		logFrom(ctx).Info("job scored", "job", names[j], "jd", jdPath, "ranked", len(out.Results), "excluded", out.Excluded)
+
+		// writeOutputs cuts the job's files to its top N; the matrix keeps
+		// every score.
+		top := out
+		// Usage and cache hits are counted for the whole batch.
+		top.Usage, top.EmbeddingCache = nil, nil
+		top.Skipped, top.Flagged = skipped, flagged
+		prog.start(StageWrite, 1)
+		top, err = writeOutputs(ctx, jobInput, top, resumeFiles)
+		prog.step(top.OutPath)
+		if err != nil {
+			return BatchOutput{}, err
+		}
+		batch.Jobs[j] = BatchJob{
+			Name:     names[j],
+			JDPath:   jdPath,
+			JDInfo:   out.JDInfo,
+			Weights:  out.Weights,
+			Rules:    out.Rules,
+			Ranked:   len(out.Results),
+			Excluded: out.Excluded,
+			OutPath:  top.OutPath,
+			Files:    top.Files,
+		}
+		batch.Mode = out.Mode
+		batch.Provider = out.Provider
+		batch.EmbeddingCache = out.EmbeddingCache
+		batch.Usage = out.Usage
+	}
+
+	bestFit := input.BestFit
+	if bestFit <= 0 {
+		bestFit = DefaultBestFit
+	}
+	batch.Candidates = buildMatrix(names, resumeDocs, outputs, bestFit)
+
+	prog.start(StageWrite, 1)
+	batch, err = writeMatrixOutputs(input, batch, matrixPath, formats)
+	prog.step(batch.OutPath)
+	return batch, err
+}
+
+// listJDFiles expands the JD folders in paths into their JD files, keeping
+// the order given and dropping duplicates.
+func listJDFiles(paths []string) ([]string, error) {
+	var files []string
+	seen := map[string]bool{}
+	add := func(path string) {
+		if !seen[path] {
+			seen[path] = true
+			files = append(files, path)
+		}
+	}
+	for _, path := range paths {
+		path = strings.TrimSpace(path)
+		if path == "" {
+			continue
+		}
+		switch {
+		case dirExists(path):
+			found, err := listResumeFiles(path)
+			if err != nil {
+				return nil, fmt.Errorf("%w: %v", ErrReadJD, err)
+			}
+			sort.Strings(found)
+			for _, f := range found {
+				add(f)
+			}
+		case fileExists(path):
+			add(path)
+		default:
+			return nil, fmt.Errorf("%w: %s", ErrMissingJD, path)
+		}
+	}
+	if len(files) == 0 {
+		return nil, ErrMissingJD
+	}
+	return files, nil
+}
+
+// jobNames names each JD after its file. A repeated name gets the first
+// free "-2", "-3"... suffix that is neither another file's name nor taken.
+func jobNames(jdPaths []string) []string {
+	names := make([]string, len(jdPaths))
+	taken := map[string]bool{}
+	for i, path := range jdPaths {
+		names[i] = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+		taken[names[i]] = true
+	}
+	used := map[string]bool{}
+	for i, name := range names {
+		if used[name] {
+			base := name
+			for n := 2; used[name] || taken[name]; n++ {
+				name = base + "-" + strconv.Itoa(n)
+			}
+			names[i] = name
+		}
+		used[name] = true
+	}
+	return names
+}
+
+// buildMatrix collects every candidate's score for every job from the full
+// rankings in outputs, in resume order, and picks their best-fit jobs: the
+// bestFit highest scores among the jobs they did not fail a knockout rule of.
+func buildMatrix(names []string, resumeDocs []resumeDoc, outputs []Output, bestFit int) []CandidateFit {
+	byFile := make([]map[string]Result, len(outputs))
+	for j, out := range outputs {
+		byFile[j] = make(map[string]Result, len(out.Results))
+		for _, r := range out.Results {
+			byFile[j][r.File] = r
+		}
+	}
+
+	rows := make([]CandidateFit, 0, len(resumeDocs))
+	for _, doc := range resumeDocs {
+		row := CandidateFit{Candidate: doc.Name, File: doc.Path, Scores: make([]JobScore, len(names))}
+		for j, name := range names {
+			r, ok := byFile[j][doc.Path]
+			if !ok {
+				row.Scores[j] = JobScore{Job: name, KnockedOut: true, Excluded: true}
+				continue
+			}
+			row.Scores[j] = JobScore{
+				Job:         name,
+				Score:       r.Score,
+				Rank:        r.Rank,
+				KnockedOut:  r.KnockedOut,
+				FailedRules: r.FailedRules,
+			}
+		}
+		fits := make([]JobScore, 0, len(names))
+		for _, s := range row.Scores {
+			if !s.KnockedOut {
+				fits = append(fits, s)
+			}
+		}
+		sort.SliceStable(fits, func(a, b int) bool { return fits[a].Score > fits[b].Score })
+		if len(fits) > bestFit {
+			fits = fits[:bestFit]
+		}
+		row.BestFit = fits
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+// writeMatrixOutputs writes the matrix in every requested format (JSONL
+// becomes JSON, since the matrix is a single document), the best-fit list and
+// the skipped report next to the matrix.
+func writeMatrixOutputs(input BatchInput, batch BatchOutput, matrixPath string, formats []string) (BatchOutput, error) {
+	var matrixFormats []string
+	for _, f := range formats {
+		if f == FormatJSONL {
+			f = FormatJSON
+		}
+		if !contains(matrixFormats, f) {
+			matrixFormats = append(matrixFormats, f)
+		}
+	}
+	batch.Files = make(map[string]string, len(matrixFormats)+1)
+	for _, f := range matrixFormats {
+		batch.Files[f] = formatPath(matrixPath, f)
+	}
+	batch.OutPath = batch.Files[matrixFormats[0]]
+	batch.Files["bestFit"] = filepath.Join(filepath.Dir(matrixPath), "best_fit.csv")
+
+	if err := os.MkdirAll(filepath.Dir(matrixPath), 0755); err != nil {
+		return BatchOutput{}, fmt.Errorf("%w: %v", ErrWriteResults, err)
+	}
+	doc := MatrixDocument{
+		Schema:        MatrixSchema,
+		SchemaVersion: MatrixSchemaVersion,
+		GeneratedAt:   time.Now().UTC().Truncate(time.Second),
+		ResumesDir:    input.ResumesDir,
+		TopN:          input.TopN,
+		BatchOutput:   batch,
+	}
+	for _, f := range matrixFormats {
+		var err error
+		switch f {
+		case FormatCSV:
+			err = writeMatrixCSV(batch.Files[f], batch)
+		case FormatJSON:
+			err = writeMatrixJSON(batch.Files[f], doc)
+		case FormatXLSX:
+			err = writeMatrixXLSX(batch.Files[f], doc)
+		}
+		if err != nil {
+			return BatchOutput{}, fmt.Errorf("%w: %v", ErrWriteResults, err)
+		}
+	}
+	if err := writeBestFitCSV(batch.Files["bestFit"], batch); err != nil {
+		return BatchOutput{}, fmt.Errorf("%w: %v", ErrWriteResults, err)
+	}
+	if err := writeSkippedCSV(skippedPath(matrixPath), batch.Skipped); err != nil {
+		return BatchOutput{}, fmt.Errorf("%w: %v", ErrWriteResults, err)
+	}
+	return batch, nil
+}
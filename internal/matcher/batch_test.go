@@ -0,0 +1,24 @@
+package matcher
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestJobNames(t *testing.T) {
+	tests := []struct {
+		paths []string
+		want  []string
+	}{
+		{[]string{"a/analyst.txt", "b/engineer.docx"}, []string{"analyst", "engineer"}},
+		{[]string{"a/analyst.txt", "b/analyst.pdf", "c/analyst.docx"}, []string{"analyst", "analyst-2", "analyst-3"}},
+		{[]string{"a/analyst.txt", "b/analyst-2.txt", "c/analyst.txt"}, []string{"analyst", "analyst-2", "analyst-3"}},
+		{[]string{"a/analyst.txt", "b/analyst.txt", "c/analyst-2.txt"}, []string{"analyst", "analyst-3", "analyst-2"}},
+		{[]string{"a/x.txt", "b/x.txt", "c/x-2.txt", "d/x-2.txt"}, []string{"x", "x-3", "x-2", "x-2-2"}},
+	}
+	for _, tt := range tests {
+		if got := jobNames(tt.paths); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("jobNames(%v) = %v, want %v", tt.paths, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,136 @@
+package matcher
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ScoreComponent is one input to the final score. Raw is the component value
+// in [0,1], Weight the normalized weight applied to it and Contribution the
+// points it adds to Score (Raw * Weight * 100).
+type ScoreComponent struct {
+	Raw          float64 `json:"raw"`
+	Weight       float64 `json:"weight"`
+	Contribution float64 `json:"contribution"`
+}
+
+// ScoreBreakdown records how a candidate's score was built. The general skill
+// lists exclude skills already reported as must or nice.
+type ScoreBreakdown struct {
+	Similarity    ScoreComponent `json:"similarity"`
+	Must          ScoreComponent `json:"must"`
+	Nice          ScoreComponent `json:"nice"`
+	Skills        ScoreComponent `json:"skills"`
+	Experience    ScoreComponent `json:"experience"`
+	MatchedMust   []string       `json:"matchedMust"`
+	MissingMust   []string       `json:"missingMust"`
+	MatchedNice   []string       `json:"matchedNice"`
+	MissingNice   []string       `json:"missingNice"`
+	MatchedSkills []string       `json:"matchedSkills"`
+	MissingSkills []string       `json:"missingSkills"`
+}
+
+// scoreCandidate computes every score component for one resume. A skill that
+// only earns parent credit is listed as matched.
+func scoreCandidate(tax *skillTaxonomy, w Weights, resSkills map[string]bool, must, nice, general []string, sim, expRatio float64) ScoreBreakdown {
+	b := ScoreBreakdown{
+		Similarity: newComponent(sim, w.Similarity),
+		Must:       newComponent(ratio(tax.coverage(resSkills, must), len(must)), w.Must),
+		Nice:       newComponent(ratio(tax.coverage(resSkills, nice), len(nice)), w.Nice),
+		Skills:     newComponent(ratio(tax.coverage(resSkills, general), len(general)), w.Skills),
+		Experience: newComponent(expRatio, w.Experience),
+	}
+	b.MatchedMust, b.MissingMust = splitMatched(tax, resSkills, must, nil)
+	b.MatchedNice, b.MissingNice = splitMatched(tax, resSkills, nice, must)
+	b.MatchedSkills, b.MissingSkills = splitMatched(tax, resSkills, general, append(append([]string{}, must...), nice...))
+	return b
+}
+
+func newComponent(raw, weight float64) ScoreComponent {
+	return ScoreComponent{
+		Raw:          raw,
+		Weight:       weight,
+		Contribution: raw * weight * 100,
+	}
+}
+
+// total is the unrounded score in points.
+func (b ScoreBreakdown) total() float64 {
+	return b.Similarity.Contribution + b.Must.Contribution + b.Nice.Contribution +
+		b.Skills.Contribution + b.Experience.Contribution
+}
+
+func (b ScoreBreakdown) explanation() string {
+	explanation := fmt.Sprintf(
+		"Similarity=%.2f; MustMatch=%.2f; NiceMatch=%.2f; SkillMatch=%.2f",
+		b.Similarity.Raw, b.Must.Raw, b.Nice.Raw, b.Skills.Raw,
+	)
+	if b.Experience.Weight > 0 {
+		explanation += fmt.Sprintf("; Experience=%.2f", b.Experience.Raw)
+	}
+	return explanation
+}
+
+func splitMatched(tax *skillTaxonomy, resSkills map[string]bool, skills, exclude []string) ([]string, []string) {
+	matched := []string{}
+	missing := []string{}
+	for _, s := range skills {
+		if contains(exclude, s) {
+			continue
+		}
+		if tax.credit(resSkills, s) > 0 {
+			matched = append(matched, s)
+		} else {
+			missing = append(missing, s)
+		}
+	}
+	return matched, missing
+}
+
+// breakdownColumns are the CSV columns written after the original ones. It is
+// an array so the XLSX writer can place the columns after it as constants.
+var breakdownColumns = [...]string{
+	"Similarity", "Similarity Weight", "Similarity Points",
+	"Must", "Must Weight", "Must Points",
+	"Nice", "Nice Weight", "Nice Points",
+	"Skills", "Skills Weight", "Skills Points",
+	"Experience", "Experience Weight", "Experience Points",
+	"Matched Must", "Missing Must",
+	"Matched Nice", "Missing Nice",
+	"Matched Skills", "Missing Skills",
+}
+
+func (b ScoreBreakdown) csvRow() []string {
+	row := make([]string, 0, len(breakdownColumns))
+	for _, c := range []ScoreComponent{b.Similarity, b.Must, b.Nice, b.Skills, b.Experience} {
+		row = append(row,
+			fmt.Sprintf("%.4f", c.Raw),
+			fmt.Sprintf("%.4f", c.Weight),
+			fmt.Sprintf("%.2f", c.Contribution),
+		)
+	}
+	for _, list := range [][]string{
+		b.MatchedMust, b.MissingMust,
+		b.MatchedNice, b.MissingNice,
+		b.MatchedSkills, b.MissingSkills,
+	} {
+		row = append(row, strings.Join(list, ", "))
+	}
+	return row
+}
+
+// cells is csvRow with typed values, for the XLSX writer.
+func (b ScoreBreakdown) cells() []any {
+	row := make([]any, 0, len(breakdownColumns))
+	for _, c := range []ScoreComponent{b.Similarity, b.Must, b.Nice, b.Skills, b.Experience} {
+		row = append(row, c.Raw, c.Weight, c.Contribution)
+	}
+	for _, list := range [][]string{
+		b.MatchedMust, b.MissingMust,
+		b.MatchedNice, b.MissingNice,
+		b.MatchedSkills, b.MissingSkills,
+	} {
+		row = append(row, strings.Join(list, ", "))
+	}
+	return row
+}
@@ -0,0 +1,286 @@
+package matcher
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ConfigFileName is the name of the project and user config files.
+const ConfigFileName = "resumegpt.yaml"
+
+// Sources of a Setting besides the environment and the default.
+const (
+	SourceFlag          = "flag"
+	SourceProjectConfig = "project config"
+	SourceUserConfig    = "user config"
+)
+
+var ErrLoadConfig = errors.New("failed to load config file")
+
+// Config is a resumegpt.yaml file. Each field stands for an environment
+// setting and only applies when that setting is not in the environment or
+// the .env file. Env sets any other setting by its variable name.
+type Config struct {
+	Provider string       `yaml:"provider"`
+	Models   ConfigModels `yaml:"models"`
+	Batch    ConfigBatch  `yaml:"batch"`
+	Workers  *int         `yaml:"workers"`
+	// Taxonomy, Profiles and Rules are relative to the config file.
+	Taxonomy string             `yaml:"taxonomy"`
+	Profiles string             `yaml:"profiles"`
+	Profile  string             `yaml:"profile"`
+	Weights  map[string]float64 `yaml:"weights"`
+	Rules    string             `yaml:"rules"`
+	Redact   string             `yaml:"redact"`
+	Formats  []string           `yaml:"formats"`
+	Report   string             `yaml:"report"`
+	LogLevel string             `yaml:"log_level"`
+	Env      map[string]string  `yaml:"env"`
+}
+
+// ConfigModels picks the provider's models.
+type ConfigModels struct {
+	LLM         string   `yaml:"llm"`
+	Embed       string   `yaml:"embed"`
+	Temperature *float64 `yaml:"temperature"`
+}
+
+// ConfigBatch sizes the provider requests.
+type ConfigBatch struct {
+	Embed              *int `yaml:"embed"`
+	EmbedChunkWords    *int `yaml:"embed_chunk_words"`
+	ExplainTopN        *int `yaml:"explain_topn"`
+	ExplainMaxChars    *int `yaml:"explain_max_chars"`
+	ExplainConcurrency *int `yaml:"explain_concurrency"`
+}
+
+// configFiles holds the config files LoadDotEnv last read: the value of each
+// setting they give, project file first, and which file it came from. They
+// are never copied into the process environment, so a reload sees edits and
+// removed keys.
+var configFiles struct {
+	mu      sync.Mutex
+	project string
+	user    string
+	values  map[string]string
+	sources map[string]string
+	err     error
+}
+
+// ConfigPaths are the project and user config files in effect; "" when
+// there is none.
+func ConfigPaths() (project, user string) {
+	configFiles.mu.Lock()
+	defer configFiles.mu.Unlock()
+	return configFiles.project, configFiles.user
+}
+
+// UserConfigPath is where the user config file is looked up: resumegpt.yaml
+// in the resumegpt folder of the user config directory.
+func UserConfigPath() string {
+	base, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(base, "resumegpt", ConfigFileName)
+}
+
+// ConfigError is the error of the last config file that failed to load, or nil.
+func ConfigError() error {
+	configFiles.mu.Lock()
+	defer configFiles.mu.Unlock()
+	return configFiles.err
+}
+
+// Getenv returns a setting: the environment (.env included) when it sets
+// key to a non-empty value, else the project config file, else the user
+// config file, else "".
+func Getenv(key string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	configFiles.mu.Lock()
+	defer configFiles.mu.Unlock()
+	return configFiles.values[key]
+}
+
+// configSetting is the config file value of key and its source, or false.
+func configSetting(key string) (string, string, bool) {
+	configFiles.mu.Lock()
+	defer configFiles.mu.Unlock()
+	v, ok := configFiles.values[key]
+	return v, configFiles.sources[key], ok
+}
+
+// loadConfigFiles reads the project config file (found like .env) and the
+// user config file, replacing what an earlier call read. The project file
+// wins over the user file.
+func loadConfigFiles() {
+	project := findUp(ConfigFileName)
+	user := UserConfigPath()
+	if user != "" && !fileExists(user) {
+		user = ""
+	}
+	if user == project {
+		user = ""
+	}
+
+	configFiles.mu.Lock()
+	defer configFiles.mu.Unlock()
+	configFiles.project, configFiles.user = project, user
+	configFiles.err = nil
+	configFiles.values = map[string]string{}
+	configFiles.sources = map[string]string{}
+	for _, f := range []struct{ path, source string }{
+		{project, SourceProjectConfig},
+		{user, SourceUserConfig},
+	} {
+		if f.path == "" {
+			continue
+		}
+		values, err := readConfigFile(f.path)
+		if err != nil {
+			configFiles.err = err
+			continue
+		}
+		for key, val := range values {
+			if _, ok := configFiles.values[key]; !ok {
+				configFiles.values[key] = val
+				configFiles.sources[key] = f.source
+			}
+		}
+	}
+}
+
+// readConfigFile returns the environment settings of a config file.
+func readConfigFile(path string) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrLoadConfig, err)
+	}
+	var cfg Config
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&cfg); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("%w: %s: %v", ErrLoadConfig, path, err)
+	}
+	values, err := cfg.settings(filepath.Dir(path))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s: %v", ErrLoadConfig, path, err)
+	}
+	return values, nil
+}
+
+// settings maps the config to environment settings, resolving paths
+// against dir.
+func (c Config) settings(dir string) (map[string]string, error) {
+	out := map[string]string{}
+	set := func(key, val string) {
+		if val = strings.TrimSpace(val); val != "" {
+			out[key] = val
+		}
+	}
+	setInt := func(key string, val *int) {
+		if val != nil {
+			out[key] = strconv.Itoa(*val)
+		}
+	}
+	setPath := func(key, val string) {
+		val = strings.TrimSpace(val)
+		if val != "" && !filepath.IsAbs(val) {
+			val = filepath.Join(dir, val)
+		}
+		set(key, val)
+	}
+
+	for key, val := range c.Env {
+		set(key, val)
+	}
+	set("RESUMEGPT_PROVIDER", c.Provider)
+	set("RESUMEGPT_LLM_MODEL", c.Models.LLM)
+	set("RESUMEGPT_EMBED_MODEL", c.Models.Embed)
+	if c.Models.Temperature != nil {
+		out["RESUMEGPT_LLM_TEMPERATURE"] = strconv.FormatFloat(*c.Models.Temperature, 'f', -1, 64)
+	}
+	setInt("RESUMEGPT_EMBED_BATCH", c.Batch.Embed)
+	setInt("RESUMEGPT_EMBED_CHUNK_WORDS", c.Batch.EmbedChunkWords)
+	setInt("RESUMEGPT_EXPLAIN_TOPN", c.Batch.ExplainTopN)
+	setInt("RESUMEGPT_EXPLAIN_MAX_CHARS", c.Batch.ExplainMaxChars)
+	setInt("RESUMEGPT_EXPLAIN_CONCURRENCY", c.Batch.ExplainConcurrency)
+	setInt("RESUMEGPT_WORKERS", c.Workers)
+	setPath("RESUMEGPT_TAXONOMY", c.Taxonomy)
+	setPath("RESUMEGPT_PROFILES", c.Profiles)
+	setPath("RESUMEGPT_RULES", c.Rules)
+	set("RESUMEGPT_PROFILE", c.Profile)
+	set("RESUMEGPT_LOG_LEVEL", c.LogLevel)
+
+	if len(c.Weights) > 0 {
+		names := make([]string, 0, len(c.Weights))
+		for name := range c.Weights {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		pairs := make([]string, len(names))
+		for i, name := range names {
+			pairs[i] = name + "=" + strconv.FormatFloat(c.Weights[name], 'f', -1, 64)
+		}
+		out["RESUMEGPT_WEIGHTS"] = strings.Join(pairs, ",")
+	}
+	if c.Redact != "" {
+		policy, err := parseRedactPolicy(c.Redact)
+		if err != nil {
+			return nil, err
+		}
+		out["RESUMEGPT_REDACT"] = policy
+	}
+	if len(c.Formats) > 0 {
+		formats, err := normalizeFormats(c.Formats)
+		if err != nil {
+			return nil, err
+		}
+		out["RESUMEGPT_FORMATS"] = strings.Join(formats, ",")
+	}
+	if c.Report != "" {
+		report, err := normalizeReport(c.Report)
+		if err != nil {
+			return nil, err
+		}
+		set("RESUMEGPT_REPORT", report)
+	}
+	return out, nil
+}
+
+// withEnvDefaults fills the run settings that flags, the workbook or the
+// desktop app left empty from the environment, config files included.
+func withEnvDefaults(input Input) (Input, error) {
+	if err := ConfigError(); err != nil {
+		return input, err
+	}
+	if strings.TrimSpace(input.Profile) == "" {
+		input.Profile = envString("RESUMEGPT_PROFILE", "")
+	}
+	if strings.TrimSpace(input.Weights) == "" {
+		input.Weights = envString("RESUMEGPT_WEIGHTS", "")
+	}
+	if len(input.Formats) == 0 {
+		formats, err := ParseFormats(Getenv("RESUMEGPT_FORMATS"))
+		if err != nil {
+			return input, err
+		}
+		input.Formats = formats
+	}
+	if strings.TrimSpace(input.Report) == "" {
+		input.Report = envString("RESUMEGPT_REPORT", "")
+	}
+	return input, nil
+}
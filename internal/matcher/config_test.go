@@ -0,0 +1,130 @@
+package matcher
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// configDirs makes a project folder (the working directory) and a user
+// config folder for the test and clears the given settings from the
+// environment.
+func configDirs(t *testing.T, keys ...string) (project, user string) {
+	t.Helper()
+	root := t.TempDir()
+	project = filepath.Join(root, "project")
+	base := filepath.Join(root, "config")
+	user = filepath.Join(base, "resumegpt")
+	for _, dir := range []string{project, user} {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeFile(t, filepath.Join(project, "go.mod"), "module test\n")
+	t.Chdir(project)
+	t.Setenv("XDG_CONFIG_HOME", base)
+	for _, key := range keys {
+		t.Setenv(key, "")
+		_ = os.Unsetenv(key)
+	}
+	t.Cleanup(loadConfigFiles)
+	return project, user
+}
+
+func writeFile(t *testing.T, path, data string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func settingSource(key string) string {
+	for _, s := range Settings() {
+		if s.Key == key {
+			return s.Source
+		}
+	}
+	return ""
+}
+
+func TestConfigPrecedence(t *testing.T) {
+	project, user := configDirs(t, "RESUMEGPT_PROFILE", "RESUMEGPT_LOG_LEVEL", "RESUMEGPT_WORKERS", "RESUMEGPT_EXPLAIN_TOPN")
+	writeFile(t, filepath.Join(project, ConfigFileName), "profile: analyst\nlog_level: debug\n")
+	writeFile(t, filepath.Join(user, ConfigFileName), "profile: engineer\nlog_level: warn\nworkers: 3\n")
+	LoadDotEnv()
+
+	tests := []struct {
+		key, want, source string
+	}{
+		{"RESUMEGPT_PROFILE", "analyst", SourceProjectConfig},
+		{"RESUMEGPT_WORKERS", "3", SourceUserConfig},
+		{"RESUMEGPT_EXPLAIN_TOPN", "", SourceDefault},
+	}
+	for _, tt := range tests {
+		if got := Getenv(tt.key); got != tt.want {
+			t.Errorf("Getenv(%s) = %q, want %q", tt.key, got, tt.want)
+		}
+		if got := settingSource(tt.key); got != tt.source {
+			t.Errorf("%s source = %q, want %q", tt.key, got, tt.source)
+		}
+	}
+	if got := envInt("RESUMEGPT_EXPLAIN_TOPN", 20); got != 20 {
+		t.Errorf("explain top N = %d, want the default 20", got)
+	}
+	if _, ok := os.LookupEnv("RESUMEGPT_PROFILE"); ok {
+		t.Error("config file value was copied into the environment")
+	}
+
+	t.Setenv("RESUMEGPT_PROFILE", "sales")
+	if got := Getenv("RESUMEGPT_PROFILE"); got != "sales" {
+		t.Errorf("env: Getenv = %q, want sales", got)
+	}
+	if got := settingSource("RESUMEGPT_PROFILE"); got != SourceEnv {
+		t.Errorf("env: source = %q, want %q", got, SourceEnv)
+	}
+
+	input, err := withEnvDefaults(Input{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if input.Profile != "sales" {
+		t.Errorf("withEnvDefaults profile = %q, want sales", input.Profile)
+	}
+	input, err = withEnvDefaults(Input{Profile: "flagged"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if input.Profile != "flagged" {
+		t.Errorf("flag profile = %q, want flagged", input.Profile)
+	}
+}
+
+func TestConfigReloadSeesEdits(t *testing.T) {
+	project, user := configDirs(t, "RESUMEGPT_LOG_LEVEL")
+	projectFile := filepath.Join(project, ConfigFileName)
+	userFile := filepath.Join(user, ConfigFileName)
+	writeFile(t, projectFile, "log_level: debug\n")
+	writeFile(t, userFile, "log_level: warn\n")
+
+	steps := []struct {
+		name          string
+		project, user string
+		want          string
+	}{
+		{"project wins", "log_level: debug\n", "log_level: warn\n", "debug"},
+		{"project edited", "log_level: error\n", "log_level: warn\n", "error"},
+		{"project key removed", "", "log_level: warn\n", "warn"},
+		{"user key removed", "", "", ""},
+	}
+	for _, step := range steps {
+		writeFile(t, projectFile, step.project)
+		writeFile(t, userFile, step.user)
+		LoadDotEnv()
+		if got := Getenv("RESUMEGPT_LOG_LEVEL"); got != step.want {
+			t.Errorf("%s: Getenv = %q, want %q", step.name, got, step.want)
+		}
+	}
+	if _, ok := os.LookupEnv("RESUMEGPT_LOG_LEVEL"); ok {
+		t.Error("config file value was copied into the environment")
+	}
+}
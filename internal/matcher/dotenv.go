@@ -5,22 +5,57 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 )
 
+// dotEnv records which .env file was read and the variables it set, for
+// Settings.
+var dotEnv struct {
+	mu   sync.Mutex
+	path string
+	keys map[string]bool
+}
+
 // LoadDotEnv tries to load a .env file from the working directory or the executable directory.
 // It also checks parent directories up to the project root (go.mod, wails.json, or .git).
-// Existing env vars are not overwritten.
+// Existing env vars are not overwritten. The resumegpt.yaml config files are
+// then reread; Getenv only falls back to them when neither the environment
+// nor .env sets a key.
 func LoadDotEnv() {
-	paths := candidateEnvPaths()
-	for _, p := range paths {
+	defer loadConfigFiles()
+	if p := findUp(".env"); p != "" {
+		dotEnv.mu.Lock()
+		dotEnv.path = p
+		dotEnv.mu.Unlock()
+		_ = loadDotEnvFile(p)
+	}
+}
+
+// DotEnvPath is the .env file LoadDotEnv read, or "" when none was found.
+func DotEnvPath() string {
+	dotEnv.mu.Lock()
+	defer dotEnv.mu.Unlock()
+	return dotEnv.path
+}
+
+func fromDotEnv(key string) bool {
+	dotEnv.mu.Lock()
+	defer dotEnv.mu.Unlock()
+	return dotEnv.keys[key]
+}
+
+// findUp returns the first existing file called name in the working
+// directory, the executable directory or their parents, or "".
+func findUp(name string) string {
+	for _, p := range candidatePaths(name) {
 		if _, err := os.Stat(p); err == nil {
-			_ = loadDotEnvFile(p)
-			return
+			return p
 		}
 	}
+	return ""
 }
 
-func candidateEnvPaths() []string {
+func candidatePaths(name string) []string {
 	paths := []string{}
 	seen := map[string]bool{}
 	add := func(p string) {
@@ -31,24 +66,24 @@ func candidateEnvPaths() []string {
 		paths = append(paths, p)
 	}
 	if wd, err := os.Getwd(); err == nil {
-		for _, p := range envPathsUp(wd) {
+		for _, p := range pathsUp(wd, name) {
 			add(p)
 		}
 	}
 	if exe, err := os.Executable(); err == nil {
 		exeDir := filepath.Dir(exe)
-		for _, p := range envPathsUp(exeDir) {
+		for _, p := range pathsUp(exeDir, name) {
 			add(p)
 		}
 	}
 	return paths
 }
 
-func envPathsUp(start string) []string {
+func pathsUp(start, name string) []string {
 	paths := []string{}
 	dir := start
 	for {
-		paths = append(paths, filepath.Join(dir, ".env"))
+		paths = append(paths, filepath.Join(dir, name))
 		if hasProjectMarker(dir) {
 			break
 		}
@@ -90,6 +125,12 @@ func loadDotEnvFile(path string) error {
 		}
 		if _, exists := os.LookupEnv(key); !exists {
 			_ = os.Setenv(key, val)
+			dotEnv.mu.Lock()
+			if dotEnv.keys == nil {
+				dotEnv.keys = map[string]bool{}
+			}
+			dotEnv.keys[key] = true
+			dotEnv.mu.Unlock()
 		}
 	}
 	return scanner.Err()
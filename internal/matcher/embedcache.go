@@ -0,0 +1,87 @@
+package matcher
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"math"
+	"os"
+	"path/filepath"
+	"regexp"
+)
+
+// EmbeddingCacheStats counts how many chunk embeddings of a run came from the
+// local cache and how many were requested from the API.
+type EmbeddingCacheStats struct {
+	Hits   int `json:"hits"`
+	Misses int `json:"misses"`
+}
+
+// embeddingCache stores one file per (model, chunk text) pair holding the
+// vector as little-endian float64s. Entries are written atomically, so the CLI
+// and the desktop app can share the cache while running at the same time.
+type embeddingCache struct {
+	dir string
+}
+
+var unsafePathRe = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// newEmbeddingCache returns nil when caching is off (RESUMEGPT_EMBED_CACHE=0)
+// or there is no cache directory.
+func newEmbeddingCache(model string) *embeddingCache {
+	dir := embeddingCacheDir()
+	if dir == "" || !envBool("RESUMEGPT_EMBED_CACHE", true) {
+		return nil
+	}
+	return &embeddingCache{dir: filepath.Join(dir, unsafePathRe.ReplaceAllString(model, "_"))}
+}
+
+func embeddingCacheDir() string {
+	base := CacheDir()
+	if base == "" {
+		return ""
+	}
+	return filepath.Join(base, "embeddings")
+}
+
+func (c *embeddingCache) path(text string) string {
+	sum := sha256.Sum256([]byte(text))
+	key := hex.EncodeToString(sum[:])
+	return filepath.Join(c.dir, key[:2], key+".bin")
+}
+
+func (c *embeddingCache) get(text string) ([]float64, bool) {
+	if c == nil {
+		return nil, false
+	}
+	data, err := os.ReadFile(c.path(text))
+	if err != nil || len(data) == 0 || len(data)%8 != 0 {
+		return nil, false
+	}
+	vec := make([]float64, len(data)/8)
+	for i := range vec {
+		vec[i] = math.Float64frombits(binary.LittleEndian.Uint64(data[i*8:]))
+	}
+	return vec, true
+}
+
+func (c *embeddingCache) put(text string, vec []float64) {
+	if c == nil || len(vec) == 0 {
+		return
+	}
+	data := make([]byte, len(vec)*8)
+	for i, v := range vec {
+		binary.LittleEndian.PutUint64(data[i*8:], math.Float64bits(v))
+	}
+	_ = writeBytesAtomic(c.path(text), data)
+}
+
+// EmbeddingCacheInfo reports where the embedding cache lives and how big it is.
+func EmbeddingCacheInfo() (CacheInfo, error) {
+	return cacheInfo(embeddingCacheDir())
+}
+
+// ClearEmbeddingCache deletes the embedding cache and returns what was removed.
+func ClearEmbeddingCache() (CacheInfo, error) {
+	return clearCache(embeddingCacheDir())
+}
@@ -13,7 +13,7 @@ var (
 )
 
 // EvaluateCandidate generates a GPT-based evaluation for a single resume.
-func EvaluateCandidate(jdPath, resumePath string) (ResumeAnalysis, error) {
+func EvaluateCandidate(ctx context.Context, jdPath, resumePath string) (ResumeAnalysis, error) {
 	LoadDotEnv()
 	if strings.TrimSpace(jdPath) == "" || !fileExists(jdPath) {
 		return ResumeAnalysis{}, ErrMissingJD
@@ -22,28 +22,30 @@ func EvaluateCandidate(jdPath, resumePath string) (ResumeAnalysis, error) {
 		return ResumeAnalysis{}, ErrMissingResume
 	}
 
-	client, err := newOpenAIClientFromEnv()
+	client, err := newAIClientFromEnv()
 	if err != nil {
 		return ResumeAnalysis{}, err
 	}
+	if client.chat == nil {
+		return ResumeAnalysis{}, fmt.Errorf("%w: the %s provider has no chat model for evaluations", ErrProvider, client.provider)
+	}
 
-	jdRaw, err := extractText(jdPath)
+	jdRaw, _, err := extractTextCached(jdPath)
 	if err != nil {
 		return ResumeAnalysis{}, fmt.Errorf("%w: %v", ErrReadJD, err)
 	}
 
-	resumeRaw, err := extractText(resumePath)
+	resumeRaw, _, err := extractTextCached(resumePath)
 	if err != nil {
 		return ResumeAnalysis{}, fmt.Errorf("%w: %v", ErrReadResume, err)
 	}
 
-	ctx := context.Background()
-	jdInfo, err := extractJDInfo(ctx, client, redactPII(jdRaw))
+	jdInfo, err := extractJDInfo(ctx, client, redactForProvider(jdRaw))
 	if err != nil {
 		return ResumeAnalysis{}, err
 	}
 
-	analysis, err := explainResume(ctx, client, jdInfo, redactPII(resumeRaw))
+	analysis, err := explainResume(ctx, client, jdInfo, redactForProvider(resumeRaw))
 	if err != nil {
 		return ResumeAnalysis{}, err
 	}
@@ -0,0 +1,208 @@
+package matcher
+
+import (
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// experienceSummary is what parseExperience can tell about a resume from its
+// employment date ranges alone.
+type experienceSummary struct {
+	Years         float64
+	SkillLastUsed map[string]int
+}
+
+// monthSpan is a range of months counted as year*12+month-1; both ends are
+// worked months, so a span covers end-start+1 months.
+type monthSpan struct {
+	start int
+	end   int
+}
+
+const datePattern = `(?:(?:jan|feb|mar|apr|may|jun|jul|aug|sep|sept|oct|nov|dec)[a-z]*\.?,?\s+\d{4}|\d{1,2}\s*/\s*\d{4}|\d{4})`
+
+var dateRangeRe = regexp.MustCompile(`(?i)\b(` + datePattern + `)\s*(?:-|–|—|to|until|till|through)\s*(` + datePattern + `|present|current|now|today|ongoing|date)\b`)
+
+var monthYearRe = regexp.MustCompile(`(?i)^([a-z]+)\.?,?\s+(\d{4})$`)
+var numericDateRe = regexp.MustCompile(`^(\d{1,2})\s*/\s*(\d{4})$`)
+
+var jdYearsRe = regexp.MustCompile(`(?i)\b(\d{1,2}(?:\.\d)?)\s*(?:\+|plus)?\s*(?:(?:-|–|to)\s*\d{1,2}\s*)?(?:years?|yrs?)\b`)
+
+var monthIndex = map[string]int{
+	"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+	"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+}
+
+var educationLineKeys = []string{
+	"university", "college", "school", "bachelor", "master", "degree",
+	"diploma", "phd", "gpa", "b.sc", "m.sc", "b.a.", "m.a.",
+}
+
+// parseExperience finds employment date ranges such as "Jan 2019 – Present",
+// "2016-2020" or "03/2018 to 11/2021", merges overlapping ranges and totals
+// them. Ranges on education lines are ignored. Each skill mentioned between a
+// range and the next one is credited with that range's end year.
+func parseExperience(tax *skillTaxonomy, raw string, now time.Time) experienceSummary {
+	nowMonth := now.Year()*12 + int(now.Month()) - 1
+	summary := experienceSummary{SkillLastUsed: map[string]int{}}
+
+	type located struct {
+		span monthSpan
+		pos  int
+	}
+	found := []located{}
+	for _, m := range dateRangeRe.FindAllStringSubmatchIndex(raw, -1) {
+		if isEducationLine(lineAround(raw, m[0])) {
+			continue
+		}
+		start, ok := parseMonth(raw[m[2]:m[3]], false, nowMonth)
+		if !ok {
+			continue
+		}
+		end, ok := parseMonth(raw[m[4]:m[5]], true, nowMonth)
+		if !ok || end < start || end > nowMonth+12 || end-start > 50*12 {
+			continue
+		}
+		found = append(found, located{span: monthSpan{start: start, end: end}, pos: m[0]})
+	}
+	if len(found) == 0 {
+		return summary
+	}
+
+	spans := make([]monthSpan, 0, len(found))
+	for i, f := range found {
+		spans = append(spans, f.span)
+
+		sectionEnd := len(raw)
+		if i+1 < len(found) {
+			sectionEnd = found[i+1].pos
+		}
+		endYear := min(f.span.end, nowMonth) / 12
+		for skill := range tax.matcher.match(skillTokens(raw[f.pos:sectionEnd])) {
+			if endYear > summary.SkillLastUsed[skill] {
+				summary.SkillLastUsed[skill] = endYear
+			}
+		}
+	}
+
+	months := 0
+	for _, s := range mergeSpans(spans) {
+		months += s.end - s.start + 1
+	}
+	summary.Years = round(float64(months) / 12)
+	return summary
+}
+
+// parseJDMinYears returns the minimum years of experience a JD asks for, such
+// as 3 for "3+ years of experience" or "3-5 years". Lines that mention
+// experience are preferred over other year counts.
+func parseJDMinYears(jdRaw string) float64 {
+	fallback := 0.0
+	for _, line := range strings.Split(jdRaw, "\n") {
+		lower := strings.ToLower(line)
+		if strings.Contains(lower, "years old") {
+			continue
+		}
+		m := jdYearsRe.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		years, err := strconv.ParseFloat(m[1], 64)
+		if err != nil || years <= 0 || years > 40 {
+			continue
+		}
+		if strings.Contains(lower, "experience") {
+			return years
+		}
+		if fallback == 0 {
+			fallback = years
+		}
+	}
+	return fallback
+}
+
+// experienceRatio scores years against the JD minimum, capped at 1.
+func experienceRatio(years, minYears float64) float64 {
+	if minYears <= 0 {
+		return 0
+	}
+	return clamp(years/minYears, 0, 1)
+}
+
+func parseMonth(s string, isEnd bool, nowMonth int) (int, bool) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	switch s {
+	case "present", "current", "now", "today", "ongoing", "date":
+		if !isEnd {
+			return 0, false
+		}
+		return nowMonth, true
+	}
+
+	year, month := 0, 0
+	if m := monthYearRe.FindStringSubmatch(s); m != nil {
+		name := m[1]
+		if len(name) > 3 {
+			name = name[:3]
+		}
+		month = monthIndex[name]
+		year, _ = strconv.Atoi(m[2])
+	} else if m := numericDateRe.FindStringSubmatch(s); m != nil {
+		month, _ = strconv.Atoi(m[1])
+		year, _ = strconv.Atoi(m[2])
+	} else if len(s) == 4 {
+		// A bare year runs from January to December, so "2016-2020"
+		// covers 2020 and "2019 - 2019" is a year, not nothing.
+		year, _ = strconv.Atoi(s)
+		month = 1
+		if isEnd {
+			month = 12
+		}
+	}
+	if month < 1 || month > 12 || year < 1950 || year*12 > nowMonth+12 {
+		return 0, false
+	}
+	if len(s) == 4 && isEnd {
+		return min(year*12+month-1, nowMonth), true
+	}
+	return year*12 + month - 1, true
+}
+
+func mergeSpans(spans []monthSpan) []monthSpan {
+	sort.Slice(spans, func(i, j int) bool {
+		return spans[i].start < spans[j].start
+	})
+	merged := []monthSpan{}
+	for _, s := range spans {
+		if n := len(merged); n > 0 && s.start <= merged[n-1].end {
+			if s.end > merged[n-1].end {
+				merged[n-1].end = s.end
+			}
+			continue
+		}
+		merged = append(merged, s)
+	}
+	return merged
+}
+
+func lineAround(text string, pos int) string {
+	start := strings.LastIndex(text[:pos], "\n") + 1
+	end := strings.Index(text[pos:], "\n")
+	if end < 0 {
+		return text[start:]
+	}
+	return text[start : pos+end]
+}
+
+func isEducationLine(line string) bool {
+	lower := strings.ToLower(line)
+	for _, k := range educationLineKeys {
+		if strings.Contains(lower, k) {
+			return true
+		}
+	}
+	return false
+}
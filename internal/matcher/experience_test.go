@@ -0,0 +1,87 @@
+package matcher
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+var experienceNow = time.Date(2025, time.June, 15, 0, 0, 0, 0, time.UTC)
+
+func month(year int, m time.Month) int {
+	return year*12 + int(m) - 1
+}
+
+func TestParseMonth(t *testing.T) {
+	now := month(2025, time.June)
+	tests := []struct {
+		in    string
+		isEnd bool
+		want  int
+		ok    bool
+	}{
+		{"Jan 2019", false, month(2019, time.January), true},
+		{"September, 2020", true, month(2020, time.September), true},
+		{"Sept. 2020", false, month(2020, time.September), true},
+		{"03/2018", false, month(2018, time.March), true},
+		{"11 / 2021", true, month(2021, time.November), true},
+		{"2016", false, month(2016, time.January), true},
+		{"2020", true, month(2020, time.December), true},
+		{"2025", true, now, true},
+		{"Present", true, now, true},
+		{"present", false, 0, false},
+		{"13/2020", false, 0, false},
+		{"1949", false, 0, false},
+		{"2030", true, 0, false},
+	}
+	for _, tt := range tests {
+		got, ok := parseMonth(tt.in, tt.isEnd, now)
+		if ok != tt.ok || (ok && got != tt.want) {
+			t.Errorf("parseMonth(%q, %v) = %d, %v; want %d, %v", tt.in, tt.isEnd, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestParseExperience(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want float64
+	}{
+		{"month name to present", "Data Analyst, Acme\nJan 2019 – Present", 6.5},
+		{"year range", "Engineer, Initech 2016-2020", 5},
+		{"single year", "Contractor 2019 – 2019", 1},
+		{"numeric dates", "Analyst 03/2018 to 11/2021", 3.75},
+		{"overlapping ranges merge", "Acme Jan 2018 - Dec 2020\nSide project Jun 2019 - Mar 2021", 3.25},
+		{"contained range", "Acme 2015 - 2020\nConsulting Mar 2016 - Apr 2017", 6},
+		{"disjoint ranges add up", "Acme Jan 2015 - Dec 2015\nInitech Jan 2018 - Dec 2018", 2},
+		{"shared month counted once", "Acme Jan 2015 - Jan 2016\nInitech Jan 2016 - Dec 2016", 2},
+		{"education lines ignored", "BSc Computer Science, State University 2012 - 2016\nAcme 2017 - 2017", 1},
+		{"no dates", "Python developer", 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := parseExperience(defaultTaxonomy, tt.text, experienceNow)
+			if got.Years != tt.want {
+				t.Errorf("years = %v, want %v", got.Years, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseExperienceSkillLastUsed(t *testing.T) {
+	text := "Acme 2014 - 2016\nJava, SQL\nInitech Mar 2019 - Present\nPython, SQL"
+	got := parseExperience(defaultTaxonomy, text, experienceNow).SkillLastUsed
+	want := map[string]int{"java": 2016, "sql": 2025, "python": 2025}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("SkillLastUsed = %v, want %v", got, want)
+	}
+}
+
+func TestMergeSpans(t *testing.T) {
+	got := mergeSpans([]monthSpan{{10, 20}, {0, 5}, {15, 30}, {5, 8}, {40, 45}})
+	want := []monthSpan{{0, 8}, {10, 30}, {40, 45}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("mergeSpans = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,417 @@
+package matcher
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+var (
+	ErrRunNotFound  = errors.New("run not found")
+	ErrRunAmbiguous = errors.New("run id is ambiguous")
+)
+
+// RunRecord is one saved run: the results document plus the settings and the
+// content hashes of every input file, so later runs can be compared with it.
+type RunRecord struct {
+	ID     string    `json:"id"`
+	Config RunConfig `json:"config"`
+	// Hashes maps every input file (JD, resumes, taxonomy, profiles, rules)
+	// to the SHA-256 of its content.
+	Hashes map[string]string `json:"hashes"`
+	ResultsDocument
+}
+
+// RunConfig is the part of Input that is not already in ResultsDocument.
+type RunConfig struct {
+	TaxonomyPath   string   `json:"taxonomyPath,omitempty"`
+	ProfilesPath   string   `json:"profilesPath,omitempty"`
+	Profile        string   `json:"profile,omitempty"`
+	Weights        string   `json:"weights,omitempty"`
+	RulesPath      string   `json:"rulesPath,omitempty"`
+	KnockoutFromJD bool     `json:"knockoutFromJd,omitempty"`
+	KnockoutMode   string   `json:"knockoutMode,omitempty"`
+	Formats        []string `json:"formats,omitempty"`
+	Report         string   `json:"report,omitempty"`
+}
+
+// RunSummary is the line shown for a run by "runs list".
+type RunSummary struct {
+	ID          string    `json:"id"`
+	GeneratedAt time.Time `json:"generatedAt"`
+	JDPath      string    `json:"jdPath"`
+	ResumesDir  string    `json:"resumesDir"`
+	Mode        string    `json:"mode"`
+	Provider    string    `json:"provider,omitempty"`
+	Profile     string    `json:"profile"`
+	Total       int       `json:"total"`
+	Ranked      int       `json:"ranked"`
+	Top         string    `json:"top,omitempty"`
+	TopScore    float64   `json:"topScore,omitempty"`
+}
+
+// HistoryDir is where runs are saved: RESUMEGPT_HISTORY_DIR, or resumegpt/runs
+// under the user config directory. Unlike the caches it is never cleared.
+func HistoryDir() string {
+	if dir := strings.TrimSpace(Getenv("RESUMEGPT_HISTORY_DIR")); dir != "" {
+		return dir
+	}
+	base, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(base, "resumegpt", "runs")
+}
+
+// historyEnabled reports whether runs are saved; RESUMEGPT_HISTORY=0 turns
+// the history off.
+func historyEnabled() bool {
+	return HistoryDir() != "" && envBool("RESUMEGPT_HISTORY", true)
+}
+
+// newRunID returns a sortable ID such as 20250102-150405-123-3fa2: the UTC
+// time to the millisecond plus random bits for runs finishing together.
+func newRunID() string {
+	var b [2]byte
+	_, _ = rand.Read(b[:])
+	now := time.Now().UTC()
+	return fmt.Sprintf("%s-%03d-%s", now.Format("20060102-150405"), now.Nanosecond()/1e6, hex.EncodeToString(b[:]))
+}
+
+// saveRun writes the record of a finished run to the history directory.
+func saveRun(input Input, doc ResultsDocument, resumeFiles []string) error {
+	rec := RunRecord{
+		ID: doc.RunID,
+		Config: RunConfig{
+			TaxonomyPath:   input.TaxonomyPath,
+			ProfilesPath:   input.ProfilesPath,
+			Profile:        input.Profile,
+			Weights:        input.Weights,
+			RulesPath:      input.RulesPath,
+			KnockoutFromJD: input.KnockoutFromJD,
+			KnockoutMode:   input.KnockoutMode,
+			Formats:        input.Formats,
+			Report:         input.Report,
+		},
+		Hashes:          map[string]string{},
+		ResultsDocument: doc,
+	}
+	inputs := append([]string{input.JDPath, input.TaxonomyPath, input.ProfilesPath, input.RulesPath}, resumeFiles...)
+	for _, path := range inputs {
+		if path == "" {
+			continue
+		}
+		sum, err := hashFile(path)
+		if err != nil {
+			// A file that disappeared mid-run is recorded without a hash.
+			continue
+		}
+		rec.Hashes[path] = sum
+	}
+	data, err := json.MarshalIndent(rec, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeBytesAtomic(filepath.Join(HistoryDir(), rec.ID+".json"), data)
+}
+
+func hashFile(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// ListRuns returns the saved runs, newest first.
+func ListRuns() ([]RunSummary, error) {
+	ids, err := runIDs()
+	if err != nil {
+		return nil, err
+	}
+	runs := make([]RunSummary, 0, len(ids))
+	for i := len(ids) - 1; i >= 0; i-- {
+		rec, err := readRun(ids[i])
+		if err != nil {
+			continue
+		}
+		s := RunSummary{
+			ID:          rec.ID,
+			GeneratedAt: rec.GeneratedAt,
+			JDPath:      rec.JDPath,
+			ResumesDir:  rec.ResumesDir,
+			Mode:        rec.Mode,
+			Provider:    rec.Provider,
+			Profile:     rec.Profile,
+			Total:       rec.Total,
+			Ranked:      len(rec.Results),
+		}
+		if len(rec.Results) > 0 {
+			s.Top = rec.Results[0].Candidate
+			s.TopScore = rec.Results[0].Score
+		}
+		runs = append(runs, s)
+	}
+	return runs, nil
+}
+
+// LoadRun reads a saved run. id may be a unique prefix of a run ID, or
+// "latest" for the newest run.
+func LoadRun(id string) (RunRecord, error) {
+	ids, err := runIDs()
+	if err != nil {
+		return RunRecord{}, err
+	}
+	id = strings.TrimSpace(id)
+	if id == "latest" && len(ids) > 0 {
+		return readRun(ids[len(ids)-1])
+	}
+	var match []string
+	for _, candidate := range ids {
+		if candidate == id {
+			return readRun(candidate)
+		}
+		if id != "" && strings.HasPrefix(candidate, id) {
+			match = append(match, candidate)
+		}
+	}
+	switch len(match) {
+	case 0:
+		return RunRecord{}, fmt.Errorf("%w: %s", ErrRunNotFound, id)
+	case 1:
+		return readRun(match[0])
+	}
+	return RunRecord{}, fmt.Errorf("%w: %s matches %s", ErrRunAmbiguous, id, strings.Join(match, ", "))
+}
+
+// runIDs lists the saved run IDs, oldest first.
+func runIDs() ([]string, error) {
+	dir := HistoryDir()
+	if dir == "" {
+		return nil, nil
+	}
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var ids []string
+	for _, e := range entries {
+		if e.IsDir() || filepath.Ext(e.Name()) != ".json" {
+			continue
+		}
+		ids = append(ids, strings.TrimSuffix(e.Name(), ".json"))
+	}
+	sort.Strings(ids)
+	return ids, nil
+}
+
+func readRun(id string) (RunRecord, error) {
+	data, err := os.ReadFile(filepath.Join(HistoryDir(), id+".json"))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return RunRecord{}, fmt.Errorf("%w: %s", ErrRunNotFound, id)
+		}
+		return RunRecord{}, err
+	}
+	var rec RunRecord
+	if err := json.Unmarshal(data, &rec); err != nil {
+		return RunRecord{}, fmt.Errorf("run %s: %v", id, err)
+	}
+	return rec, nil
+}
+
+// RunDiff compares two runs: what changed in their settings and inputs, and
+// how each candidate moved. The full ranking of both runs is compared, not
+// just the top N, with candidates keyed by their resume's path relative to
+// ResumesDir.
+type RunDiff struct {
+	From     string            `json:"from"`
+	To       string            `json:"to"`
+	Settings []SettingChange   `json:"settings"`
+	Changed  []CandidateChange `json:"changed"`
+	Added    []CandidateChange `json:"added"`
+	Removed  []CandidateChange `json:"removed"`
+}
+
+// SettingChange is one setting or input file that differs between two runs.
+type SettingChange struct {
+	Name string `json:"name"`
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// CandidateChange is a candidate's rank and score in both runs; the rank and
+// score of the run it is missing from are zero, and so is ScoreDelta.
+type CandidateChange struct {
+	Candidate  string  `json:"candidate"`
+	File       string  `json:"file"`
+	FromRank   int     `json:"fromRank,omitempty"`
+	ToRank     int     `json:"toRank,omitempty"`
+	FromScore  float64 `json:"fromScore,omitempty"`
+	ToScore    float64 `json:"toScore,omitempty"`
+	ScoreDelta float64 `json:"scoreDelta"`
+}
+
+// RankDelta is the number of places the candidate moved up (negative: down).
+func (c CandidateChange) RankDelta() int {
+	return c.FromRank - c.ToRank
+}
+
+// DiffRuns compares run a (older) with run b. Candidates are matched by
+// resume path relative to the resumes folder, so renaming a file shows up as
+// a removal and an addition.
+func DiffRuns(a, b RunRecord) RunDiff {
+	d := RunDiff{
+		From:     a.ID,
+		To:       b.ID,
+		Settings: settingChanges(a, b),
+		Changed:  []CandidateChange{},
+		Added:    []CandidateChange{},
+		Removed:  []CandidateChange{},
+	}
+	before := make(map[string]Result, len(a.Results))
+	for _, r := range a.Results {
+		before[resumeKey(a, r.File)] = r
+	}
+	seen := map[string]bool{}
+	for _, r := range b.Results {
+		key := resumeKey(b, r.File)
+		seen[key] = true
+		old, ok := before[key]
+		if !ok {
+			d.Added = append(d.Added, CandidateChange{Candidate: r.Candidate, File: r.File, ToRank: r.Rank, ToScore: r.Score})
+			continue
+		}
+		d.Changed = append(d.Changed, CandidateChange{
+			Candidate:  r.Candidate,
+			File:       r.File,
+			FromRank:   old.Rank,
+			ToRank:     r.Rank,
+			FromScore:  old.Score,
+			ToScore:    r.Score,
+			ScoreDelta: round(r.Score - old.Score),
+		})
+	}
+	for _, r := range a.Results {
+		if !seen[resumeKey(a, r.File)] {
+			d.Removed = append(d.Removed, CandidateChange{Candidate: r.Candidate, File: r.File, FromRank: r.Rank, FromScore: r.Score})
+		}
+	}
+	return d
+}
+
+func settingChanges(a, b RunRecord) []SettingChange {
+	changes := []SettingChange{}
+	add := func(name, from, to string) {
+		if from != to {
+			changes = append(changes, SettingChange{Name: name, From: from, To: to})
+		}
+	}
+	add("jd", a.JDPath, b.JDPath)
+	add("jd content", shortHash(a.Hashes[a.JDPath]), shortHash(b.Hashes[b.JDPath]))
+	add("resumes", a.ResumesDir, b.ResumesDir)
+	if from, to := resumeFileChanges(a, b); from != "" {
+		add("resume files", from, to)
+	}
+	add("top n", fmt.Sprint(a.TopN), fmt.Sprint(b.TopN))
+	add("mode", a.Mode, b.Mode)
+	add("provider", a.Provider, b.Provider)
+	add("taxonomy", a.Taxonomy, b.Taxonomy)
+	add("profile", a.Profile, b.Profile)
+	add("weights", weightsString(a.Weights), weightsString(b.Weights))
+	add("knockout mode", a.KnockoutMode, b.KnockoutMode)
+	add("knockout rules", ruleNames(a.Rules), ruleNames(b.Rules))
+	if a.JDInfo != nil && b.JDInfo != nil {
+		add("must skills", strings.Join(a.JDInfo.SkillsMust, ", "), strings.Join(b.JDInfo.SkillsMust, ", "))
+		add("nice skills", strings.Join(a.JDInfo.SkillsNice, ", "), strings.Join(b.JDInfo.SkillsNice, ", "))
+		add("min years", fmt.Sprint(a.JDInfo.YearsExperienceMin), fmt.Sprint(b.JDInfo.YearsExperienceMin))
+	}
+	return changes
+}
+
+// resumeHashes maps the resume files of a run, by path relative to the
+// resumes folder, to their hashes. Relative paths keep same-named files in
+// different subfolders apart and still match across moved folders.
+func resumeHashes(rec RunRecord) map[string]string {
+	skip := map[string]bool{rec.JDPath: true, rec.Config.TaxonomyPath: true, rec.Config.ProfilesPath: true, rec.Config.RulesPath: true}
+	hashes := map[string]string{}
+	for path, sum := range rec.Hashes {
+		if skip[path] {
+			continue
+		}
+		hashes[resumeKey(rec, path)] = sum
+	}
+	return hashes
+}
+
+// resumeKey is path relative to the run's resumes folder, or path itself
+// when it is outside the folder.
+func resumeKey(rec RunRecord, path string) string {
+	name, err := filepath.Rel(rec.ResumesDir, path)
+	if err != nil || strings.HasPrefix(name, "..") {
+		name = path
+	}
+	return filepath.ToSlash(name)
+}
+
+// resumeFileChanges describes the resume files of both runs, counting the
+// files edited, added and removed; both are empty when nothing changed.
+func resumeFileChanges(a, b RunRecord) (string, string) {
+	before, after := resumeHashes(a), resumeHashes(b)
+	edited, added, removed := 0, 0, 0
+	for name, sum := range after {
+		old, ok := before[name]
+		switch {
+		case !ok:
+			added++
+		case old != sum:
+			edited++
+		}
+	}
+	for name := range before {
+		if _, ok := after[name]; !ok {
+			removed++
+		}
+	}
+	if edited+added+removed == 0 {
+		return "", ""
+	}
+	return fmt.Sprintf("%d files", len(before)), fmt.Sprintf("%d files (%d edited, %d added, %d removed)", len(after), edited, added, removed)
+}
+
+func shortHash(sum string) string {
+	if len(sum) > 12 {
+		return sum[:12]
+	}
+	return sum
+}
+
+func weightsString(w Weights) string {
+	return fmt.Sprintf("similarity=%.2f,must=%.2f,nice=%.2f,skills=%.2f,experience=%.2f",
+		w.Similarity, w.Must, w.Nice, w.Skills, w.Experience)
+}
+
+func ruleNames(rules []KnockoutRule) string {
+	names := make([]string, 0, len(rules))
+	for _, r := range rules {
+		names = append(names, r.Name)
+	}
+	return strings.Join(names, "; ")
+}
@@ -0,0 +1,42 @@
+package matcher
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestDiffRunsMatchesRelativePaths(t *testing.T) {
+	run := func(id, dir string, results ...Result) RunRecord {
+		rec := RunRecord{ID: id}
+		rec.ResumesDir = dir
+		for i := range results {
+			results[i].File = filepath.Join(dir, results[i].File)
+		}
+		rec.Results = results
+		return rec
+	}
+	a := run("a", "/old/resumes",
+		Result{Candidate: "ann", File: "ann.pdf", Rank: 1, Score: 0.9},
+		Result{Candidate: "bob", File: "team/bob.pdf", Rank: 2, Score: 0.8},
+		Result{Candidate: "cid", File: "cid.pdf", Rank: 3, Score: 0.7},
+	)
+	b := run("b", "/new/resumes",
+		Result{Candidate: "bob", File: "team/bob.pdf", Rank: 1, Score: 0.95},
+		Result{Candidate: "ann", File: "ann.pdf", Rank: 2, Score: 0.9},
+		Result{Candidate: "bob", File: "bob.pdf", Rank: 3, Score: 0.5},
+	)
+
+	d := DiffRuns(a, b)
+	if len(d.Changed) != 2 || len(d.Added) != 1 || len(d.Removed) != 1 {
+		t.Fatalf("changed %d, added %d, removed %d, want 2, 1, 1", len(d.Changed), len(d.Added), len(d.Removed))
+	}
+	if c := d.Changed[0]; c.Candidate != "bob" || c.RankDelta() != 1 || c.ScoreDelta != 0.15 {
+		t.Errorf("bob moved %d places by %v, want 1 by 0.15", c.RankDelta(), c.ScoreDelta)
+	}
+	if got := d.Added[0].File; got != filepath.Join("/new/resumes", "bob.pdf") {
+		t.Errorf("added %s, want the top-level bob.pdf", got)
+	}
+	if got := d.Removed[0].Candidate; got != "cid" {
+		t.Errorf("removed %s, want cid", got)
+	}
+}
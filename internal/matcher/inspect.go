@@ -0,0 +1,88 @@
+package matcher
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var ErrExtract = errors.New("failed to extract text")
+
+// ExtractText returns the text extracted from a resume or JD, the same text
+// a run scores. With redact set, it is redacted as RESUMEGPT_REDACT says
+// before anything is sent to a provider: emails and phone numbers, plus the
+// demographic words under the default pii policy.
+func ExtractText(path string, redact bool) (string, error) {
+	LoadDotEnv()
+	if strings.TrimSpace(path) == "" || !fileExists(path) {
+		return "", fmt.Errorf("%w: file not found: %s", ErrExtract, path)
+	}
+	raw, _, err := extractTextCached(path)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrExtract, err)
+	}
+	if redact {
+		return redactForProvider(raw), nil
+	}
+	return raw, nil
+}
+
+// JDExplanation is what a run reads from a job description.
+type JDExplanation struct {
+	// Mode is "openai" when the provider's chat model extracted the
+	// requirements and "heuristic" when they were parsed from the text.
+	Mode     string    `json:"mode"`
+	Provider string    `json:"provider,omitempty"`
+	JD       JDExtract `json:"jd"`
+}
+
+// ExplainJD extracts the requirements of a job description the way Run
+// does: with the configured provider's chat model, falling back to the
+// heuristic must/nice parsing when no API key is set or heuristic is true.
+func ExplainJD(ctx context.Context, jdPath, taxonomyPath string, heuristic bool) (JDExplanation, error) {
+	LoadDotEnv()
+	if strings.TrimSpace(jdPath) == "" || !fileExists(jdPath) {
+		return JDExplanation{}, ErrMissingJD
+	}
+	tax, err := loadTaxonomy(taxonomyPath)
+	if err != nil {
+		return JDExplanation{}, err
+	}
+	jdRaw, jdNorm, err := extractTextCached(jdPath)
+	if err != nil {
+		return JDExplanation{}, fmt.Errorf("%w: %v", ErrReadJD, err)
+	}
+
+	mustSkills, niceSkills := findMustNiceSkills(tax, jdRaw)
+	parsed := JDExtract{
+		SkillsMust:         mustSkills,
+		SkillsNice:         niceSkills,
+		SkillsOther:        extractSkills(tax, jdNorm, topTerms(jdNorm, 25)),
+		YearsExperienceMin: parseJDMinYears(jdRaw),
+	}
+	if heuristic {
+		return JDExplanation{Mode: "heuristic", JD: parsed}, nil
+	}
+
+	client, err := newAIClientFromEnv()
+	if err != nil {
+		if envBool("RESUMEGPT_REQUIRE_OPENAI", false) || errors.Is(err, ErrProvider) {
+			return JDExplanation{}, err
+		}
+		return JDExplanation{Mode: "heuristic", JD: parsed}, nil
+	}
+	if client.chat == nil {
+		return JDExplanation{Mode: "heuristic", Provider: client.provider, JD: parsed}, nil
+	}
+	jdInfo, err := extractJDInfo(ctx, client, redactForProvider(jdRaw))
+	if err != nil {
+		return JDExplanation{}, err
+	}
+	// Same skills and fallbacks as runOpenAI.
+	jdInfo.SkillsMust, jdInfo.SkillsNice, jdInfo.SkillsOther, _ = jdSkills(tax, jdInfo, jdRaw, parsed.SkillsOther)
+	if jdInfo.YearsExperienceMin <= 0 {
+		jdInfo.YearsExperienceMin = parsed.YearsExperienceMin
+	}
+	return JDExplanation{Mode: client.mode(), Provider: client.provider, JD: jdInfo}, nil
+}
@@ -0,0 +1,231 @@
+package matcher
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// JobsInput ranks open jobs for one candidate. Only the scoring fields of
+// Input are used (TopN, the taxonomy, profile, weights, knockout rules and
+// workers); nothing is written to disk.
+type JobsInput struct {
+	Input
+	ResumePath string
+	// JDPaths lists JD files and folders of JDs.
+	JDPaths []string
+}
+
+// JobsOutput is the candidate's open jobs, best fit first.
+type JobsOutput struct {
+	Candidate  string     `json:"candidate"`
+	ResumePath string     `json:"resumePath"`
+	Jobs       []JobMatch `json:"jobs"`
+	// Mode is "heuristic", "openai" or "local" (RESUMEGPT_PROVIDER=local).
+	Mode         string `json:"mode"`
+	Provider     string `json:"provider,omitempty"`
+	Total        int    `json:"total"`
+	Excluded     int    `json:"excluded"`
+	Profile      string `json:"profile"`
+	Taxonomy     string `json:"taxonomy,omitempty"`
+	KnockoutMode string `json:"knockoutMode"`
+	// Warning is set when the resume has very little text.
+	Warning        string               `json:"warning,omitempty"`
+	EmbeddingCache *EmbeddingCacheStats `json:"embeddingCache,omitempty"`
+	Usage          *Usage               `json:"usage,omitempty"`
+}
+
+// JobMatch is the candidate scored against one JD, with the same components
+// as a Result. Breakdown lists the JD's matched and missing must-have and
+// nice-to-have skills.
+type JobMatch struct {
+	Rank        int            `json:"rank"`
+	Job         string         `json:"job"`
+	JDPath      string         `json:"jdPath"`
+	Score       float64        `json:"score"`
+	Strengths   string         `json:"strengths"`
+	Weaknesses  string         `json:"weaknesses"`
+	Explanation string         `json:"explanation"`
+	Breakdown   ScoreBreakdown `json:"breakdown"`
+	KnockedOut  bool           `json:"knockedOut"`
+	FailedRules []string       `json:"failedRules,omitempty"`
+	Warning     string         `json:"warning,omitempty"`
+	JDInfo      *JDExtract     `json:"jdInfo,omitempty"`
+	Weights     Weights        `json:"weights"`
+}
+
+// MatchJobs scores one resume against every JD and ranks the JDs the way Run
+// ranks resumes: knocked out JDs go last, or are dropped in exclude mode.
+// In OpenAI mode the top JDs (RESUMEGPT_EXPLAIN_TOPN) get an LLM explanation.
+func MatchJobs(ctx context.Context, input JobsInput) (JobsOutput, error) {
+	return matchJobsInternal(ctx, input, false)
+}
+
+// MatchJobsHeuristic is MatchJobs with the heuristic ranking (no OpenAI).
+func MatchJobsHeuristic(ctx context.Context, input JobsInput) (JobsOutput, error) {
+	return matchJobsInternal(ctx, input, true)
+}
+
+func matchJobsInternal(ctx context.Context, input JobsInput, forceHeuristic bool) (JobsOutput, error) {
+	LoadDotEnv()
+	var err error
+	input.Input, err = withEnvDefaults(input.Input)
+	if err != nil {
+		return JobsOutput{}, err
+	}
+	if strings.TrimSpace(input.ResumePath) == "" || !fileExists(input.ResumePath) {
+		return JobsOutput{}, ErrMissingResume
+	}
+	jdPaths, err := listJDFiles(input.JDPaths)
+	if err != nil {
+		return JobsOutput{}, err
+	}
+	tax, err := loadTaxonomy(input.TaxonomyPath)
+	if err != nil {
+		return JobsOutput{}, err
+	}
+	profile, err := selectProfile(input.ProfilesPath, input.Profile, input.Weights)
+	if err != nil {
+		return JobsOutput{}, err
+	}
+	rules, err := loadRules(input.RulesPath, input.KnockoutFromJD, input.KnockoutMode)
+	if err != nil {
+		return JobsOutput{}, err
+	}
+
+	prog := newProgressReporter(input.Progress, logFrom(ctx))
+	defer prog.finish()
+	docs, skipped, _, err := loadResumes(ctx, []string{input.ResumePath}, 1, prog)
+	if err != nil {
+		return JobsOutput{}, err
+	}
+	if len(docs) == 0 {
+		return JobsOutput{}, fmt.Errorf("%w: %s", ErrReadResume, skipped[0].Reason)
+	}
+	doc := docs[0]
+
+	jdRaws := make([]string, len(jdPaths))
+	for i, path := range jdPaths {
+		jdRaws[i], _, err = extractTextCached(path)
+		if err != nil {
+			return JobsOutput{}, fmt.Errorf("%w: %s: %v", ErrReadJD, path, err)
+		}
+	}
+
+	client, err := selectClient(ctx, forceHeuristic)
+	if err != nil {
+		return JobsOutput{}, err
+	}
+	var jdVecs [][]float64
+	var resumeVec []float64
+	explainN := 0
+	if client != nil {
+		texts := make([]string, 0, len(jdRaws)+1)
+		for _, raw := range jdRaws {
+			texts = append(texts, redactForProvider(raw))
+		}
+		vecs, err := fitAndEmbed(ctx, client, tax, append(texts, doc.Redacted), prog)
+		if err != nil {
+			return JobsOutput{}, err
+		}
+		jdVecs, resumeVec = vecs[:len(jdRaws)], vecs[len(jdRaws)]
+		// The JDs are only explained once they are ranked, so runOpenAI
+		// must not explain the lone candidate of every JD.
+		if client.chat != nil {
+			explainN = client.explainTopN
+		}
+		client.explainTopN = 0
+	}
+
+	names := jobNames(jdPaths)
+	out := JobsOutput{
+		Candidate:    doc.Name,
+		ResumePath:   doc.Path,
+		Mode:         "heuristic",
+		Total:        len(jdPaths),
+		Profile:      profile.Name,
+		Taxonomy:     tax.version,
+		KnockoutMode: rules.mode,
+		Warning:      doc.Warning,
+	}
+	matches := make([]JobMatch, 0, len(jdPaths))
+	results := make([]Result, 0, len(jdPaths))
+	jdInfos := make([]JDExtract, 0, len(jdPaths))
+	for j, jdPath := range jdPaths {
+		var scored Output
+		if client != nil {
+			scored, err = runOpenAI(ctx, prog, input.Input, tax, profile, rules, jdRaws[j], docs, 1, client, [][]float64{jdVecs[j], resumeVec})
+		} else {
+			scored, err = runHeuristic(ctx, prog, input.Input, tax, profile, rules, jdRaws[j], docs, 1)
+		}
+		if err != nil {
+			return JobsOutput{}, err
+		}
+		out.Mode = scored.Mode
+		out.Provider = scored.Provider
+		if len(scored.Results) == 0 {
+			out.Excluded++
+			continue
+		}
+		matches = append(matches, JobMatch{Job: names[j], JDPath: jdPath, JDInfo: scored.JDInfo, Weights: scored.Weights})
+		results = append(results, scored.Results[0])
+		jdInfos = append(jdInfos, *scored.JDInfo)
+	}
+
+	// Same order as rankResults: passing JDs first, then by score.
+	order := make([]int, len(matches))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		ra, rb := results[order[a]], results[order[b]]
+		if ra.KnockedOut != rb.KnockedOut {
+			return !ra.KnockedOut
+		}
+		return ra.Score > rb.Score
+	})
+	if input.TopN > 0 && len(order) > input.TopN {
+		order = order[:input.TopN]
+	}
+
+	if explainN > len(order) {
+		explainN = len(order)
+	}
+	if explainN > 0 {
+		tasks := make([]explainTask, explainN)
+		for k, i := range order[:explainN] {
+			tasks[k] = explainTask{result: &results[i], jd: jdInfos[i], resume: doc.Redacted, logKey: "jd", name: matches[i].JDPath}
+		}
+		if err := explainAll(ctx, prog, client, tax, tasks); err != nil {
+			return JobsOutput{}, err
+		}
+	}
+
+	out.Jobs = make([]JobMatch, len(order))
+	for rank, i := range order {
+		m, r := matches[i], results[i]
+		m.Rank = rank + 1
+		m.Score = r.Score
+		m.Strengths = r.Strengths
+		m.Weaknesses = r.Weaknesses
+		m.Explanation = r.Explanation
+		m.Breakdown = r.Breakdown
+		m.KnockedOut = r.KnockedOut
+		m.FailedRules = r.FailedRules
+		m.Warning = r.Warning
+		out.Jobs[rank] = m
+	}
+	if client != nil {
+		if client.embedCache != nil {
+			stats := client.embeddingStats()
+			out.EmbeddingCache = &stats
+		}
+		if client.usage != nil {
+			u := client.usage.snapshot()
+			u.BudgetUSD = client.budgetUSD
+			out.Usage = &u
+		}
+	}
+	return out, nil
+}
@@ -0,0 +1,256 @@
+package matcher
+
+import (
+	"context"
+	"hash/fnv"
+	"math"
+	"sync"
+)
+
+const (
+	localDims    = 256
+	localNonZero = 8
+	// localWindow is how many words on each side count as a word's context.
+	localWindow = 3
+	// localSkillWeight scales the taxonomy skill features against plain words.
+	localSkillWeight = 3.0
+)
+
+// localEmbedder is a pure-Go embedder for machines without network access
+// (RESUMEGPT_PROVIDER=local). Every word gets a fixed random index vector
+// derived from its hash, plus hashed character trigrams so inflections such as
+// "analyst" and "analytics" land close together. fit adds a context vector
+// learned by random indexing over the documents being ranked and the optional
+// RESUMEGPT_LOCAL_CORPUS folder, so words used in the same contexts drift
+// together. Skills found by the taxonomy add a feature for their canonical
+// name and their parents, which puts aliases like "k8s" and "kubernetes" on
+// the same spot.
+type localEmbedder struct {
+	corpusDir string
+
+	mu           sync.Mutex
+	corpus       [][]string
+	corpusLoaded bool
+	tax          *skillTaxonomy
+	parents      map[string][]string
+	idf          map[string]float64
+	defaultIDF   float64
+	contexts     map[string][]float32
+}
+
+// corpusFitter is implemented by embedders that learn from the documents of a
+// run before embedding them.
+type corpusFitter interface {
+	fit(tax *skillTaxonomy, docs []string)
+}
+
+func newLocalEmbedder(corpusDir string) *localEmbedder {
+	return &localEmbedder{corpusDir: corpusDir, tax: defaultTaxonomy, defaultIDF: 1}
+}
+
+// fit learns word weights and context vectors from docs and the corpus folder.
+func (e *localEmbedder) fit(tax *skillTaxonomy, docs []string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if !e.corpusLoaded {
+		e.corpus = loadLocalCorpus(e.corpusDir)
+		e.corpusLoaded = true
+	}
+	all := make([][]string, 0, len(docs)+len(e.corpus))
+	for _, doc := range docs {
+		all = append(all, skillTokens(doc))
+	}
+	all = append(all, e.corpus...)
+
+	df := map[string]int{}
+	for _, tokens := range all {
+		seen := map[string]bool{}
+		for _, tok := range tokens {
+			if !seen[tok] {
+				seen[tok] = true
+				df[tok]++
+			}
+		}
+	}
+	n := float64(len(all))
+	e.idf = make(map[string]float64, len(df))
+	for tok, count := range df {
+		e.idf[tok] = math.Log((1+n)/(1+float64(count))) + 1
+	}
+	e.defaultIDF = math.Log(1+n) + 1
+
+	// Words seen in a single document carry no context worth learning and
+	// would only grow the table.
+	e.contexts = map[string][]float32{}
+	for _, tokens := range all {
+		for i, tok := range tokens {
+			if df[tok] < 2 {
+				continue
+			}
+			vec := e.contexts[tok]
+			if vec == nil {
+				vec = make([]float32, localDims)
+				e.contexts[tok] = vec
+			}
+			for j := i - localWindow; j <= i+localWindow; j++ {
+				if j < 0 || j == i || j >= len(tokens) {
+					continue
+				}
+				dist := i - j
+				if dist < 0 {
+					dist = -dist
+				}
+				addIndexVector32(vec, tokens[j], float32(1)/float32(dist))
+			}
+		}
+	}
+	for _, vec := range e.contexts {
+		normalize32(vec)
+	}
+
+	e.tax = tax
+	e.parents = map[string][]string{}
+	for parent, children := range tax.children {
+		for _, child := range children {
+			e.parents[child] = append(e.parents[child], parent)
+		}
+	}
+}
+
+func (e *localEmbedder) Embed(ctx context.Context, texts []string) ([][]float64, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	out := make([][]float64, len(texts))
+	for i, text := range texts {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		out[i] = e.embed(skillTokens(text))
+	}
+	return out, nil
+}
+
+func (e *localEmbedder) embed(tokens []string) []float64 {
+	vec := make([]float64, localDims)
+	tf := map[string]int{}
+	for _, tok := range tokens {
+		tf[tok]++
+	}
+	for tok, count := range tf {
+		idf, ok := e.idf[tok]
+		if !ok {
+			idf = e.defaultIDF
+		}
+		e.addWord(vec, tok, (1+math.Log(float64(count)))*idf)
+	}
+	for skill := range e.tax.matcher.match(tokens) {
+		addIndexVector(vec, "skill:"+skill, localSkillWeight)
+		for _, parent := range e.parents[skill] {
+			addIndexVector(vec, "skill:"+parent, localSkillWeight*e.tax.parentCredit)
+		}
+	}
+	normalize(vec)
+	return vec
+}
+
+// addWord adds weight times the word's vector: its index vector, its
+// character trigrams and its learned context.
+func (e *localEmbedder) addWord(vec []float64, word string, weight float64) {
+	addIndexVector(vec, word, weight)
+	padded := "#" + word + "#"
+	if grams := len(padded) - 2; grams > 0 {
+		for i := 0; i < grams; i++ {
+			addIndexVector(vec, "tri:"+padded[i:i+3], 0.5*weight/float64(grams))
+		}
+	}
+	if context, ok := e.contexts[word]; ok {
+		for i, v := range context {
+			vec[i] += weight * float64(v)
+		}
+	}
+}
+
+// indexPositions returns the fixed sparse +1/-1 positions of key.
+func indexPositions(key string, fn func(pos int, sign float64)) {
+	h := fnv.New64a()
+	h.Write([]byte(key))
+	state := h.Sum64()
+	for i := 0; i < localNonZero; i++ {
+		state = splitmix64(state)
+		sign := 1.0
+		if state&1 == 1 {
+			sign = -1
+		}
+		fn(int((state>>1)%localDims), sign)
+	}
+}
+
+func addIndexVector(vec []float64, key string, weight float64) {
+	indexPositions(key, func(pos int, sign float64) {
+		vec[pos] += sign * weight
+	})
+}
+
+func addIndexVector32(vec []float32, key string, weight float32) {
+	indexPositions(key, func(pos int, sign float64) {
+		vec[pos] += float32(sign) * weight
+	})
+}
+
+func splitmix64(x uint64) uint64 {
+	x += 0x9e3779b97f4a7c15
+	x = (x ^ (x >> 30)) * 0xbf58476d1ce4e5b9
+	x = (x ^ (x >> 27)) * 0x94d049bb133111eb
+	return x ^ (x >> 31)
+}
+
+func normalize(vec []float64) {
+	norm := 0.0
+	for _, v := range vec {
+		norm += v * v
+	}
+	if norm == 0 {
+		return
+	}
+	norm = math.Sqrt(norm)
+	for i := range vec {
+		vec[i] /= norm
+	}
+}
+
+func normalize32(vec []float32) {
+	norm := 0.0
+	for _, v := range vec {
+		norm += float64(v) * float64(v)
+	}
+	if norm == 0 {
+		return
+	}
+	scale := float32(1 / math.Sqrt(norm))
+	for i := range vec {
+		vec[i] *= scale
+	}
+}
+
+// loadLocalCorpus reads every supported file under dir. Unreadable files are
+// ignored; the corpus only sharpens the context vectors.
+func loadLocalCorpus(dir string) [][]string {
+	if dir == "" {
+		return nil
+	}
+	files, err := listResumeFiles(dir)
+	if err != nil {
+		return nil
+	}
+	corpus := make([][]string, len(files))
+	parallelFor(len(files), workerCount(0), func(i int) {
+		raw, _, err := extractTextCached(files[i])
+		if err != nil {
+			return
+		}
+		corpus[i] = skillTokens(redactPII(raw))
+	})
+	return corpus
+}
@@ -1,7 +1,6 @@
 package matcher
 
 import (
-    "bufio"
     "context"
     "encoding/csv"
     "encoding/json"
@@ -14,6 +13,7 @@ import (
     "sort"
     "strings"
     "time"
+    "unicode"
 
     "github.com/ledongthuc/pdf"
     "github.com/nguyenthenguyen/docx"
@@ -27,21 +27,76 @@ type Result struct {
     Weaknesses  string  `json:"weaknesses"`
     Explanation string  `json:"explanation"`
     File        string  `json:"file"`
+    Breakdown   ScoreBreakdown `json:"breakdown"`
+    KnockedOut  bool           `json:"knockedOut"`
+    FailedRules []string       `json:"failedRules,omitempty"`
+    Warning     string         `json:"warning,omitempty"`
     Extracted   *ResumeExtract `json:"extracted,omitempty"`
 }
 
 type Input struct {
-    JDPath     string
-    ResumesDir string
-    TopN       int
-    OutPath    string
+    JDPath          string
+    ResumesDir      string
+    TopN            int
+    OutPath         string
+    TaxonomyPath    string
+    ProfilesPath    string
+    Profile         string
+    // Weights overrides individual weights of the profile, e.g. "must=0.5,nice=0.1".
+    Weights         string
+    RulesPath       string
+    // KnockoutFromJD turns the JD's minimum years, certifications and
+    // education into knockout rules.
+    KnockoutFromJD  bool
+    // KnockoutMode is "rank" (failing candidates rank last) or "exclude".
+    KnockoutMode    string
+    // Workers bounds parallel extraction and scoring; 0 uses
+    // RESUMEGPT_WORKERS or the number of CPUs.
+    Workers         int
+    // Formats lists the result files to write ("csv", "json", "jsonl",
+    // "xlsx"). Empty picks the format from OutPath's extension, csv by default.
+    Formats         []string
+    // Report is an extra report to write next to the results; "html" is the
+    // only one so far.
+    Report          string
+    // ResultsWorkbook, when set, is an existing workbook (such as the macro
+    // workbook) whose Results and Run sheets are rewritten as well.
+    ResultsWorkbook string
+    // LogLevel is the level of the run's JSON log (debug, info, warn, error
+    // or off); empty uses RESUMEGPT_LOG_LEVEL, info by default.
+    LogLevel        string
+    // Progress, when set, is called as the run moves through its stages.
+    Progress        ProgressFunc
 }
 
 type Output struct {
-    Results []Result `json:"results"`
-    OutPath string   `json:"outPath"`
-    Total   int      `json:"total"`
-    JDInfo  *JDExtract `json:"jdInfo,omitempty"`
+    Results        []Result             `json:"results"`
+    OutPath        string               `json:"outPath"`
+    // Files maps every written format to its path; OutPath is the first.
+    Files          map[string]string    `json:"files,omitempty"`
+    // Mode is "heuristic", "openai" or "local" (RESUMEGPT_PROVIDER=local).
+    Mode           string               `json:"mode"`
+    Total          int                  `json:"total"`
+    JDInfo         *JDExtract           `json:"jdInfo,omitempty"`
+    Taxonomy       string               `json:"taxonomy,omitempty"`
+    Profile        string               `json:"profile"`
+    Weights        Weights              `json:"weights"`
+    KnockoutMode   string               `json:"knockoutMode"`
+    Rules          []KnockoutRule       `json:"rules,omitempty"`
+    Excluded       int                  `json:"excluded"`
+    Skipped        []SkippedFile        `json:"skipped"`
+    Flagged        []SkippedFile        `json:"flagged"`
+    // Provider is set in OpenAI mode only; EmbeddingCache also needs the
+    // embedding cache to be on.
+    Provider       string               `json:"provider,omitempty"`
+    EmbeddingCache *EmbeddingCacheStats `json:"embeddingCache,omitempty"`
+    // Usage is set in OpenAI mode for providers that report tokens.
+    Usage          *Usage               `json:"usage,omitempty"`
+    // RunID names the run in the history store; empty when the history is
+    // off or could not be written.
+    RunID          string               `json:"runId,omitempty"`
+    // LogPath is the run's JSON log; empty when logging is off.
+    LogPath        string               `json:"logPath,omitempty"`
 }
 
 type JDExtract struct {
@@ -62,6 +117,7 @@ type ResumeExtract struct {
     Education       []string `json:"education"`
     Certifications  []string `json:"certifications"`
     Titles          []string `json:"titles"`
+    SkillLastUsed   map[string]int `json:"skill_last_used,omitempty"`
 }
 
 type ResumeAnalysis struct {
@@ -81,6 +137,7 @@ type resumeDoc struct {
     Raw      string
     Redacted string
     Norm     string
+    Warning  string
 }
 
 var stopwords = map[string]bool{
@@ -104,54 +161,9 @@ var redactTerms = []string{
     "citizenship", "nationality", "veteran", "disability", "disabled",
 }
 
-var skillLexicon = []string{
-    "python", "java", "c", "c++", "c#", "go", "golang", "rust", "scala", "kotlin",
-    "swift", "objective-c", "javascript", "typescript", "ruby", "php", "perl",
-    "matlab", "r", "sas", "stata", "julia", "sql", "pl/sql", "t-sql", "nosql",
-    "html", "css", "sass", "less", "json", "xml", "yaml", "graphql", "rest", "grpc",
-    "api", "microservices", "soa", "oop", "design patterns", "clean architecture",
-    "react", "react.js", "angular", "vue", "svelte", "next.js", "nuxt", "node.js",
-    "nodejs", "express", "nestjs", "django", "flask", "fastapi", "spring", "spring boot",
-    "asp.net", ".net", "entity framework", "laravel", "rails", "ruby on rails",
-    "gin", "echo", "fiber", "wails", "electron", "qt",
-    "android", "ios", "react native", "flutter", "xamarin", "cordova",
-    "aws", "amazon web services", "azure", "gcp", "google cloud", "oracle cloud",
-    "docker", "kubernetes", "helm", "terraform", "ansible", "chef", "puppet",
-    "jenkins", "github actions", "gitlab ci", "circleci", "ci/cd", "devops",
-    "linux", "windows", "macos", "bash", "powershell", "shell scripting",
-    "git", "svn", "mercurial",
-    "postgresql", "mysql", "mariadb", "sql server", "oracle", "sqlite", "mongodb",
-    "cassandra", "redis", "dynamodb", "elasticsearch", "opensearch", "neo4j",
-    "snowflake", "bigquery", "redshift", "databricks",
-    "kafka", "rabbitmq", "activemq", "nats", "sqs", "pubsub",
-    "spark", "hadoop", "hive", "pig", "airflow", "dbt", "etl", "elt", "data pipeline",
-    "data warehouse", "data lake", "data modeling", "data governance",
-    "machine learning", "deep learning", "nlp", "computer vision", "llm",
-    "data analysis", "data analytics", "data science", "statistics",
-    "feature engineering", "modeling", "forecasting", "recommendation systems",
-    "pandas", "numpy", "scikit-learn", "tensorflow", "pytorch", "keras", "xgboost",
-    "lightgbm", "catboost", "mlops", "model deployment", "onnx",
-    "excel", "power bi", "tableau", "looker", "qlik", "superset", "mode",
-    "salesforce", "sap", "oracle erp", "netsuite", "workday",
-    "serviceNow", "jira", "confluence", "slack", "microsoft teams",
-    "testing", "unit testing", "integration testing", "e2e testing", "tdd", "bdd",
-    "jest", "mocha", "cypress", "playwright", "selenium", "pytest", "junit",
-    "security", "oauth", "openid connect", "saml", "jwt", "encryption",
-    "identity", "iam", "zero trust", "vulnerability management",
-    "networking", "tcp/ip", "dns", "http", "https", "ssl", "tls", "load balancing",
-    "observability", "monitoring", "logging", "tracing", "prometheus", "grafana",
-    "datadog", "new relic", "splunk",
-    "product management", "project management", "agile", "scrum", "kanban",
-    "leadership", "stakeholder management", "communication", "requirements",
-    "documentation", "technical writing",
-    "ui/ux", "figma", "sketch", "adobe xd", "user research", "wireframing",
-    "seo", "marketing", "growth", "analytics", "a/b testing",
-    "accounting", "finance", "budgeting", "forecasting", "procurement",
-    "hr", "recruiting", "talent acquisition", "payroll", "benefits",
-    "customer support", "sales", "business development", "crm",
-    "compliance", "risk management", "gdpr", "hipaa", "sox", "pci",
-    "warehouse", "logistics", "supply chain", "operations",
-}
+// redactKeep maps a word of redactTerms to the word that makes it part of a
+// skill name when it comes right before, so "React Native" keeps "native".
+var redactKeep = map[string]string{"native": "react"}
 
 var emailRe = regexp.MustCompile(`[\w\.-]+@[\w\.-]+`)
 var phoneRe = regexp.MustCompile(`\+?\d[\d\s\-]{7,}`)
@@ -167,17 +179,89 @@ var (
     ErrMissingOpenAIKey = errors.New("openai api key not configured")
 )
 
-func Run(input Input) (Output, error) {
-    return runInternal(input, false)
+// Run ranks the resumes of input against its JD. Canceling ctx stops the run
+// and returns ctx.Err() without writing any output.
+func Run(ctx context.Context, input Input) (Output, error) {
+    return runInternal(ctx, input, false)
 }
 
 // RunHeuristic always uses the legacy heuristic ranking (no OpenAI).
-func RunHeuristic(input Input) (Output, error) {
-    return runInternal(input, true)
+func RunHeuristic(ctx context.Context, input Input) (Output, error) {
+    return runInternal(ctx, input, true)
 }
 
-func runInternal(input Input, forceHeuristic bool) (Output, error) {
+func runInternal(ctx context.Context, input Input, forceHeuristic bool) (Output, error) {
     LoadDotEnv()
+    input, err := withEnvDefaults(input)
+    if err != nil {
+        return Output{}, err
+    }
+    if err := checkLogLevel(input); err != nil {
+        return Output{}, err
+    }
+    runID := newRunID()
+    log, logPath, closeLog := openRunLog(input, resultsPath(input), runID)
+    defer closeLog()
+    log.Info("run started",
+        "jd", input.JDPath,
+        "resumes", input.ResumesDir,
+        "top_n", input.TopN,
+        "out", resultsPath(input),
+        "profile", input.Profile,
+        "heuristic_only", forceHeuristic,
+    )
+
+    started := time.Now()
+    out, err := runStages(withLogger(ctx, log), input, forceHeuristic, runID, logPath)
+    took := durationMS(time.Since(started))
+    switch {
+    case err == nil:
+        attrs := []any{
+            "duration_ms", took,
+            "mode", out.Mode,
+            "total", out.Total,
+            "ranked", len(out.Results),
+            "skipped", len(out.Skipped),
+            "flagged", len(out.Flagged),
+            "profile", out.Profile,
+            "knockout", out.KnockoutMode,
+            "rules", len(out.Rules),
+            "excluded", out.Excluded,
+            "files", out.Files,
+        }
+        if out.Provider != "" {
+            attrs = append(attrs, "provider", out.Provider)
+        }
+        if out.EmbeddingCache != nil {
+            attrs = append(attrs, "embedding_cache", out.EmbeddingCache)
+        }
+        if out.Usage != nil {
+            attrs = append(attrs, "usage", out.Usage)
+        }
+        if input.ResultsWorkbook != "" {
+            attrs = append(attrs, "workbook", input.ResultsWorkbook)
+        }
+        if out.RunID != "" {
+            attrs = append(attrs, "history", filepath.Join(HistoryDir(), out.RunID+".json"))
+        }
+        log.Info("run finished", attrs...)
+    case errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded):
+        log.Warn("run canceled", "duration_ms", took, "error", err)
+    default:
+        log.Error("run failed", "duration_ms", took, "error", err)
+    }
+    return out, err
+}
+
+// resultsPath is Input.OutPath, outputs/results.csv by default.
+func resultsPath(input Input) string {
+    if outPath := strings.TrimSpace(input.OutPath); outPath != "" {
+        return outPath
+    }
+    return filepath.Join("outputs", "results.csv")
+}
+
+func runStages(ctx context.Context, input Input, forceHeuristic bool, runID, logPath string) (Output, error) {
     if strings.TrimSpace(input.JDPath) == "" || !fileExists(input.JDPath) {
         return Output{}, ErrMissingJD
     }
@@ -185,7 +269,26 @@ func runInternal(input Input, forceHeuristic bool) (Output, error) {
         return Output{}, ErrMissingResumes
     }
 
-    jdRaw, err := extractText(input.JDPath)
+    tax, err := loadTaxonomy(input.TaxonomyPath)
+    if err != nil {
+        return Output{}, err
+    }
+    profile, err := selectProfile(input.ProfilesPath, input.Profile, input.Weights)
+    if err != nil {
+        return Output{}, err
+    }
+    rules, err := loadRules(input.RulesPath, input.KnockoutFromJD, input.KnockoutMode)
+    if err != nil {
+        return Output{}, err
+    }
+    if _, err := normalizeFormats(input.Formats); err != nil {
+        return Output{}, err
+    }
+    if _, err := normalizeReport(input.Report); err != nil {
+        return Output{}, err
+    }
+
+    jdRaw, _, err := extractTextCached(input.JDPath)
     if err != nil {
         return Output{}, fmt.Errorf("%w: %v", ErrReadJD, err)
     }
@@ -198,48 +301,137 @@ func runInternal(input Input, forceHeuristic bool) (Output, error) {
         return Output{}, ErrNoResumes
     }
     totalResumes := len(resumeFiles)
+    prog := newProgressReporter(input.Progress, logFrom(ctx))
+    defer prog.finish()
 
-    resumeDocs := make([]resumeDoc, 0, len(resumeFiles))
-    for _, path := range resumeFiles {
-        raw, err := extractText(path)
-        if err != nil {
-            continue
-        }
-        resumeDocs = append(resumeDocs, resumeDoc{
-            Path:     path,
-            Name:     strings.TrimSuffix(filepath.Base(path), filepath.Ext(path)),
-            Raw:      raw,
-            Redacted: redactPII(raw),
-            Norm:     normalizeText(raw),
-        })
+    resumeDocs, skipped, flagged, err := loadResumes(ctx, resumeFiles, workerCount(input.Workers), prog)
+    if err != nil {
+        return Output{}, err
     }
     if len(resumeDocs) == 0 {
-        return Output{}, ErrNoResumes
+        return Output{}, fmt.Errorf("%w: none of the %d files could be read (%s: %s)", ErrNoResumes, totalResumes, skipped[0].Path, skipped[0].Reason)
     }
 
+    out, err := scoreResumes(ctx, prog, input, forceHeuristic, tax, profile, rules, jdRaw, resumeDocs, totalResumes)
+    if err != nil {
+        return Output{}, err
+    }
+    out.Skipped = skipped
+    out.Flagged = flagged
+    out.LogPath = logPath
+    if historyEnabled() {
+        out.RunID = runID
+    }
+    prog.start(StageWrite, 1)
+    out, err = writeOutputs(ctx, input, out, resumeFiles)
+    prog.step(out.OutPath)
+    return out, err
+}
+
+func scoreResumes(ctx context.Context, prog *progressReporter, input Input, forceHeuristic bool, tax *skillTaxonomy, profile ScoringProfile, rules *ruleSet, jdRaw string, resumeDocs []resumeDoc, totalResumes int) (Output, error) {
+    aiClient, err := selectClient(ctx, forceHeuristic)
+    if err != nil {
+        return Output{}, err
+    }
+    var out Output
+    if aiClient != nil {
+        out, err = runOpenAI(ctx, prog, input, tax, profile, rules, jdRaw, resumeDocs, totalResumes, aiClient, nil)
+    } else {
+        out, err = runHeuristic(ctx, prog, input, tax, profile, rules, jdRaw, resumeDocs, totalResumes)
+    }
+    if err != nil {
+        return Output{}, err
+    }
+    return out, nil
+}
+
+// explainTask is one explanation for explainAll: the JD and redacted resume
+// sent to the chat model and the result it fills. logKey and name identify
+// it in the run log and the progress.
+type explainTask struct {
+    result *Result
+    jd     JDExtract
+    resume string
+    logKey string
+    name   string
+}
+
+// explainAll writes the explanations of tasks. Each task only touches its
+// own result, so they run concurrently; the client's limiter keeps them under
+// the rate limit. Once the cost budget is reached no new explanation is
+// started and calls already in flight still finish. A failed explanation is
+// a warning on its result; only cancellation is returned.
+func explainAll(ctx context.Context, prog *progressReporter, client *aiClient, tax *skillTaxonomy, tasks []explainTask) error {
+    log := logFrom(ctx)
+    prog.start(StageExplain, len(tasks))
+    parallelFor(len(tasks), client.explainWorkers, func(i int) {
+        if ctx.Err() != nil {
+            return
+        }
+        t := tasks[i]
+        defer prog.step(t.name)
+        if client.budgetUSD > 0 && client.usage.cost() >= client.budgetUSD {
+            client.usage.skipExplain()
+            log.Warn("explanation skipped: cost budget reached", t.logKey, t.name, "budget_usd", client.budgetUSD)
+            t.result.Warning = joinWarnings(t.result.Warning, "explanation skipped: cost budget reached")
+            return
+        }
+        analysis, err := explainResume(ctx, client, t.jd, t.resume)
+        if err != nil && ctx.Err() != nil {
+            return
+        }
+        if err != nil {
+            log.Warn("explanation failed", t.logKey, t.name, "error", err)
+            t.result.Warning = joinWarnings(t.result.Warning, "explanation failed: "+err.Error())
+            return
+        }
+        applyAnalysis(tax, t.result, analysis)
+    })
+    return ctx.Err()
+}
+
+// selectClient returns the configured provider's client, or nil when the run
+// uses heuristic mode.
+func selectClient(ctx context.Context, forceHeuristic bool) (*aiClient, error) {
+    log := logFrom(ctx)
     if forceHeuristic {
-        return runHeuristic(input, jdRaw, resumeDocs, totalResumes)
+        log.Info("mode selected", "mode", "heuristic", "reason", "heuristic ranking requested")
+        return nil, nil
     }
 
     aiRequired := envBool("RESUMEGPT_REQUIRE_OPENAI", false)
-    aiClient, aiErr := newOpenAIClientFromEnv()
+    aiClient, aiErr := newAIClientFromEnv()
     if aiErr == nil {
-        return runOpenAI(input, jdRaw, resumeDocs, totalResumes, aiClient)
+        log.Info("mode selected", "mode", aiClient.mode(), "provider", aiClient.provider, "reason", "provider configured")
+        return aiClient, nil
     }
-    if aiRequired {
-        if errors.Is(aiErr, ErrMissingOpenAIKey) {
-            return Output{}, ErrMissingOpenAIKey
-        }
-        return Output{}, aiErr
+    // A missing key for the default provider falls back to heuristic mode; a
+    // provider that was chosen or misconfigured never does, since that would
+    // silently ignore the user's setup.
+    if aiRequired || errors.Is(aiErr, ErrProvider) {
+        return nil, aiErr
     }
-    return runHeuristic(input, jdRaw, resumeDocs, totalResumes)
+    log.Warn("mode selected", "mode", "heuristic", "reason", "no AI provider available", "error", aiErr)
+    return nil, nil
 }
 
-func runHeuristic(input Input, jdRaw string, resumeDocs []resumeDoc, totalResumes int) (Output, error) {
+// runHeuristic ranks every resume; the caller cuts the results to the top N.
+func runHeuristic(ctx context.Context, prog *progressReporter, input Input, tax *skillTaxonomy, profile ScoringProfile, rules *ruleSet, jdRaw string, resumeDocs []resumeDoc, totalResumes int) (Output, error) {
     jdNorm := normalizeText(jdRaw)
     jdTerms := topTerms(jdNorm, 25)
-    jdSkills := extractSkills(jdNorm, jdTerms)
-    mustSkills, niceSkills := findMustNiceSkills(jdRaw)
+    jdSkills := extractSkills(tax, jdNorm, jdTerms)
+    mustSkills, niceSkills := findMustNiceSkills(tax, jdRaw)
+    minYears := parseJDMinYears(jdRaw)
+    now := time.Now()
+
+    jdInfo := JDExtract{
+        SkillsMust:         mustSkills,
+        SkillsNice:         niceSkills,
+        SkillsOther:        jdSkills,
+        YearsExperienceMin: minYears,
+    }
+    ruleList := rules.withJD(jdInfo, mustSkills)
+    logFrom(ctx).Debug("jd requirements", "must", mustSkills, "nice", niceSkills, "other", jdSkills, "min_years", minYears, "rules", len(ruleList))
 
     docs := []string{jdNorm}
     resumeTexts := make([]string, 0, len(resumeDocs))
@@ -255,173 +447,196 @@ func runHeuristic(input Input, jdRaw string, resumeDocs []resumeDoc, totalResume
     vectors := buildTfidfVectors(docs)
     jdVec := vectors[0]
 
-    wCos, wMust, wNice, wSkill := scoreWeights(len(mustSkills))
+    weights := profile.resolve(len(mustSkills), minYears > 0)
 
-    results := make([]Result, 0, len(resumeTexts))
-    for i, text := range resumeTexts {
-        resSkills := extractSkills(text, jdTerms)
+    results := make([]Result, len(resumeTexts))
+    prog.start(StageScore, len(resumeTexts))
+    parallelFor(len(resumeTexts), workerCount(input.Workers), func(i int) {
+        if ctx.Err() != nil {
+            return
+        }
+        defer prog.step(resumeFiles[i])
+        text := resumeTexts[i]
+        exp := parseExperience(tax, resumeDocs[i].Raw, now)
+        resSkills := extractSkills(tax, text, jdTerms)
         resSkillSet := map[string]bool{}
         for _, s := range resSkills {
             resSkillSet[s] = true
         }
 
-        mustMatch := 0
-        for _, s := range mustSkills {
-            if resSkillSet[s] {
-                mustMatch++
-            }
-        }
-        niceMatch := 0
-        for _, s := range niceSkills {
-            if resSkillSet[s] {
-                niceMatch++
-            }
-        }
-        skillMatch := 0
-        for _, s := range jdSkills {
-            if resSkillSet[s] {
-                skillMatch++
-            }
-        }
-
-        mustRatio := ratio(mustMatch, len(mustSkills))
-        niceRatio := ratio(niceMatch, len(niceSkills))
-        skillRatio := ratio(skillMatch, len(jdSkills))
+        expRatio := experienceRatio(exp.Years, minYears)
 
         sim := cosineSim(jdVec, vectors[i+1])
 
-        score := (wCos * sim) + (wMust * mustRatio) + (wNice * niceRatio) + (wSkill * skillRatio)
-        scorePct := round(score * 100)
+        breakdown := scoreCandidate(tax, weights, resSkillSet, mustSkills, niceSkills, jdSkills, sim, expRatio)
+        scorePct := round(breakdown.total())
 
         strengths := buildStrengths(resSkillSet, mustSkills, niceSkills, jdSkills)
         weaknesses := buildWeaknesses(resSkillSet, mustSkills, niceSkills, jdSkills)
+        failed := failedRules(tax, ruleList, resumeDocs[i], resSkillSet, exp.Years)
 
-        explanation := fmt.Sprintf(
-            "Similarity=%.2f; MustMatch=%.2f; NiceMatch=%.2f; SkillMatch=%.2f",
-            sim, mustRatio, niceRatio, skillRatio,
-        )
-
-        results = append(results, Result{
+        results[i] = Result{
             Candidate:   resumeNames[i],
             Score:       scorePct,
             Strengths:   joinOrNone(strengths),
             Weaknesses:  joinOrNone(weaknesses),
-            Explanation: explanation,
+            Explanation: breakdown.explanation(),
             File:        resumeFiles[i],
-        })
-    }
-
-    sort.Slice(results, func(i, j int) bool {
-        return results[i].Score > results[j].Score
+            Breakdown:   breakdown,
+            KnockedOut:  len(failed) > 0,
+            FailedRules: failed,
+            Warning:     resumeDocs[i].Warning,
+            Extracted: &ResumeExtract{
+                Skills:          resSkills,
+                YearsExperience: exp.Years,
+                SkillLastUsed:   exp.SkillLastUsed,
+            },
+        }
     })
-    for i := range results {
-        results[i].Rank = i + 1
-    }
-
-    if input.TopN > 0 && len(results) > input.TopN {
-        results = results[:input.TopN]
-    }
-
-    outPath := strings.TrimSpace(input.OutPath)
-    if outPath == "" {
-        outPath = filepath.Join("outputs", "results.csv")
-    }
-
-    if err := writeResultsCSV(outPath, results); err != nil {
-        return Output{}, fmt.Errorf("%w: %v", ErrWriteResults, err)
+    if err := ctx.Err(); err != nil {
+        return Output{}, err
     }
-    appendLog(outPath, totalResumes)
 
-    return Output{Results: results, OutPath: outPath, Total: totalResumes}, nil
+    scored := len(results)
+    results = rankResults(results, rules.mode)
+    excluded := scored - len(results)
+
+    return Output{
+        Results:      results,
+        Mode:         "heuristic",
+        Total:        totalResumes,
+        JDInfo:       &jdInfo,
+        Taxonomy:     tax.version,
+        Profile:      profile.Name,
+        Weights:      weights,
+        KnockoutMode: rules.mode,
+        Rules:        ruleList,
+        Excluded:     excluded,
+    }, nil
 }
 
-func runOpenAI(input Input, jdRaw string, resumeDocs []resumeDoc, totalResumes int, client *openAIClient) (Output, error) {
-    ctx := context.Background()
-    jdRedacted := redactPII(jdRaw)
+// runOpenAI ranks every resume and explains the top ones; the caller cuts
+// the results to the top N. embeddings holds the JD's and then each resume's
+// vector when they are already known, and is computed when nil.
+func runOpenAI(ctx context.Context, prog *progressReporter, input Input, tax *skillTaxonomy, profile ScoringProfile, rules *ruleSet, jdRaw string, resumeDocs []resumeDoc, totalResumes int, client *aiClient, embeddings [][]float64) (Output, error) {
+    jdRedacted := redactForProvider(jdRaw)
     jdNorm := normalizeText(jdRaw)
 
-    jdInfo, err := extractJDInfo(ctx, client, jdRedacted)
-    if err != nil {
-        return Output{}, err
+    // Without a chat model (the local provider) the requirements come from
+    // the same JD parsing heuristic mode uses.
+    jdInfo := JDExtract{}
+    if client.chat != nil {
+        prog.start(StageJD, 1)
+        var err error
+        jdInfo, err = extractJDInfo(ctx, client, jdRedacted)
+        if err != nil {
+            return Output{}, err
+        }
+        prog.step("")
     }
 
     jdTerms := topTerms(jdNorm, 25)
-    fallbackSkills := extractSkills(jdNorm, jdTerms)
-
-    mustSkills := cleanSkillList(jdInfo.SkillsMust)
-    niceSkills := cleanSkillList(jdInfo.SkillsNice)
-    otherSkills := cleanSkillList(jdInfo.SkillsOther)
-
-    if len(mustSkills) == 0 && len(niceSkills) == 0 && len(otherSkills) == 0 {
-        mustSkills, niceSkills = findMustNiceSkills(jdRaw)
-        otherSkills = fallbackSkills
+    fallbackSkills := extractSkills(tax, jdNorm, jdTerms)
+
+    mustSkills, niceSkills, otherSkills, fromText := jdSkills(tax, jdInfo, jdRaw, fallbackSkills)
+
+    log := logFrom(ctx)
+    if fromText {
+        if client.chat != nil {
+            log.Warn("jd extraction found no skills, parsing the jd text instead")
+        } else {
+            jdInfo.SkillsMust = mustSkills
+            jdInfo.SkillsNice = niceSkills
+            jdInfo.SkillsOther = otherSkills
+        }
     }
 
     allSkills := mergeUnique(mustSkills, niceSkills, otherSkills, fallbackSkills)
 
-    docTexts := make([]string, 0, len(resumeDocs)+1)
-    docTexts = append(docTexts, jdRedacted)
-    for _, doc := range resumeDocs {
-        docTexts = append(docTexts, doc.Redacted)
+    minYears := jdInfo.YearsExperienceMin
+    if minYears <= 0 {
+        minYears = parseJDMinYears(jdRaw)
     }
-    embeddings, err := embedDocuments(ctx, client, docTexts)
-    if err != nil {
-        return Output{}, err
+    now := time.Now()
+
+    jdInfo.YearsExperienceMin = minYears
+    ruleList := rules.withJD(jdInfo, mustSkills)
+    log.Debug("jd requirements", "must", mustSkills, "nice", niceSkills, "other", otherSkills, "min_years", minYears, "rules", len(ruleList))
+
+    if embeddings == nil {
+        docTexts := make([]string, 0, len(resumeDocs)+1)
+        docTexts = append(docTexts, jdRedacted)
+        for _, doc := range resumeDocs {
+            docTexts = append(docTexts, doc.Redacted)
+        }
+        var err error
+        embeddings, err = fitAndEmbed(ctx, client, tax, docTexts, prog)
+        if err != nil {
+            return Output{}, err
+        }
     }
     jdVec := embeddings[0]
 
-    wCos, wMust, wNice, wSkill := scoreWeights(len(mustSkills))
+    weights := profile.resolve(len(mustSkills), minYears > 0)
 
-    results := make([]Result, 0, len(resumeDocs))
     resumeByPath := make(map[string]resumeDoc, len(resumeDocs))
-    for i, doc := range resumeDocs {
+    for _, doc := range resumeDocs {
         resumeByPath[doc.Path] = doc
-        vec := embeddings[i+1]
+    }
 
-        resSkillSet := skillsInText(doc.Norm, allSkills)
-        mustMatch := countMatches(resSkillSet, mustSkills)
-        niceMatch := countMatches(resSkillSet, niceSkills)
-        skillMatch := countMatches(resSkillSet, allSkills)
+    results := make([]Result, len(resumeDocs))
+    prog.start(StageScore, len(resumeDocs))
+    parallelFor(len(resumeDocs), workerCount(input.Workers), func(i int) {
+        if ctx.Err() != nil {
+            return
+        }
+        defer prog.step(resumeDocs[i].Path)
+        doc := resumeDocs[i]
+        vec := embeddings[i+1]
 
-        mustRatio := ratio(mustMatch, len(mustSkills))
-        niceRatio := ratio(niceMatch, len(niceSkills))
-        skillRatio := ratio(skillMatch, len(allSkills))
+        resSkillSet := skillsInText(tax, doc.Norm, allSkills)
+        exp := parseExperience(tax, doc.Raw, now)
+        expRatio := experienceRatio(exp.Years, minYears)
 
         sim := cosineSimVec(jdVec, vec)
 
-        score := (wCos * sim) + (wMust * mustRatio) + (wNice * niceRatio) + (wSkill * skillRatio)
-        scorePct := round(score * 100)
+        breakdown := scoreCandidate(tax, weights, resSkillSet, mustSkills, niceSkills, allSkills, sim, expRatio)
+        scorePct := round(breakdown.total())
 
         strengths := buildStrengths(resSkillSet, mustSkills, niceSkills, allSkills)
         weaknesses := buildWeaknesses(resSkillSet, mustSkills, niceSkills, allSkills)
+        failed := failedRules(tax, ruleList, doc, resSkillSet, exp.Years)
 
-        explanation := fmt.Sprintf(
-            "Similarity=%.2f; MustMatch=%.2f; NiceMatch=%.2f; SkillMatch=%.2f",
-            sim, mustRatio, niceRatio, skillRatio,
-        )
-
-        results = append(results, Result{
+        results[i] = Result{
             Candidate:   doc.Name,
             Score:       scorePct,
             Strengths:   joinOrNone(strengths),
             Weaknesses:  joinOrNone(weaknesses),
-            Explanation: explanation,
+            Explanation: breakdown.explanation(),
             File:        doc.Path,
-        })
-    }
-
-    sort.Slice(results, func(i, j int) bool {
-        return results[i].Score > results[j].Score
+            Breakdown:   breakdown,
+            KnockedOut:  len(failed) > 0,
+            FailedRules: failed,
+            Warning:     doc.Warning,
+            Extracted: &ResumeExtract{
+                Skills:          sortedKeys(resSkillSet),
+                YearsExperience: exp.Years,
+                SkillLastUsed:   exp.SkillLastUsed,
+            },
+        }
     })
-    for i := range results {
-        results[i].Rank = i + 1
+    if err := ctx.Err(); err != nil {
+        return Output{}, err
     }
 
-    if input.TopN > 0 && len(results) > input.TopN {
-        results = results[:input.TopN]
-    }
+    scored := len(results)
+    results = rankResults(results, rules.mode)
+    excluded := scored - len(results)
 
     explainN := client.explainTopN
+    if client.chat == nil {
+        explainN = 0
+    }
     if input.TopN > 0 && input.TopN < explainN {
         explainN = input.TopN
     }
@@ -429,43 +644,84 @@ func runOpenAI(input Input, jdRaw string, resumeDocs []resumeDoc, totalResumes i
         explainN = len(results)
     }
 
-    for i := 0; i < explainN; i++ {
-        doc, ok := resumeByPath[results[i].File]
-        if !ok {
-            continue
-        }
-        analysis, err := explainResume(ctx, client, jdInfo, doc.Redacted)
-        if err != nil {
-            continue
-        }
-        results[i].Strengths = joinOrNone(analysis.Strengths)
-        results[i].Weaknesses = joinOrNone(analysis.Weaknesses)
-        if strings.TrimSpace(analysis.Summary) != "" {
-            results[i].Explanation = analysis.Summary
-        }
-        results[i].Extracted = &ResumeExtract{
-            Skills:          cleanSkillList(analysis.Skills),
-            YearsExperience: analysis.YearsExperience,
-            Education:       cleanList(analysis.Education),
-            Certifications:  cleanList(analysis.Certifications),
-            Titles:          cleanList(analysis.Titles),
+    tasks := make([]explainTask, 0, explainN)
+    for i := range explainN {
+        if doc, ok := resumeByPath[results[i].File]; ok {
+            tasks = append(tasks, explainTask{result: &results[i], jd: jdInfo, resume: doc.Redacted, logKey: "file", name: results[i].File})
         }
     }
-
-    outPath := strings.TrimSpace(input.OutPath)
-    if outPath == "" {
-        outPath = filepath.Join("outputs", "results.csv")
+    if err := explainAll(ctx, prog, client, tax, tasks); err != nil {
+        return Output{}, err
     }
 
-    if err := writeResultsCSV(outPath, results); err != nil {
-        return Output{}, fmt.Errorf("%w: %v", ErrWriteResults, err)
+    var embedStats *EmbeddingCacheStats
+    if client.embedCache != nil {
+        stats := client.embeddingStats()
+        embedStats = &stats
+    }
+    var usage *Usage
+    if client.usage != nil {
+        u := client.usage.snapshot()
+        u.BudgetUSD = client.budgetUSD
+        usage = &u
+    }
+    return Output{
+        Results:        results,
+        Mode:           client.mode(),
+        Total:          totalResumes,
+        JDInfo:         &jdInfo,
+        Taxonomy:       tax.version,
+        Profile:        profile.Name,
+        Weights:        weights,
+        KnockoutMode:   rules.mode,
+        Rules:          ruleList,
+        Excluded:       excluded,
+        Provider:       client.provider,
+        EmbeddingCache: embedStats,
+        Usage:          usage,
+    }, nil
+}
+
+// applyAnalysis replaces the computed strengths, weaknesses, explanation and
+// resume fields of r with an LLM explanation's. The scores are kept.
+func applyAnalysis(tax *skillTaxonomy, r *Result, analysis ResumeAnalysis) {
+    r.Strengths = joinOrNone(analysis.Strengths)
+    r.Weaknesses = joinOrNone(analysis.Weaknesses)
+    if strings.TrimSpace(analysis.Summary) != "" {
+        r.Explanation = analysis.Summary
+    }
+    parsed := r.Extracted
+    r.Extracted = &ResumeExtract{
+        Skills:          tax.canonicalList(analysis.Skills),
+        YearsExperience: analysis.YearsExperience,
+        Education:       cleanList(analysis.Education),
+        Certifications:  cleanList(analysis.Certifications),
+        Titles:          cleanList(analysis.Titles),
+    }
+    if parsed != nil {
+        r.Extracted.SkillLastUsed = parsed.SkillLastUsed
+        if r.Extracted.YearsExperience == 0 {
+            r.Extracted.YearsExperience = parsed.YearsExperience
+        }
     }
-    appendLog(outPath, totalResumes)
+}
 
-    return Output{Results: results, OutPath: outPath, Total: totalResumes, JDInfo: &jdInfo}, nil
+// jdSkills returns the must, nice and other skills of a JD extracted by the
+// model, canonicalised through the taxonomy. When the model found none, they
+// are parsed from the JD text as in heuristic mode, with fallback as the
+// other skills, and fromText is set.
+func jdSkills(tax *skillTaxonomy, jdInfo JDExtract, jdRaw string, fallback []string) (must, nice, other []string, fromText bool) {
+    must = tax.canonicalList(jdInfo.SkillsMust)
+    nice = tax.canonicalList(jdInfo.SkillsNice)
+    other = tax.canonicalList(jdInfo.SkillsOther)
+    if len(must) > 0 || len(nice) > 0 || len(other) > 0 {
+        return must, nice, other, false
+    }
+    must, nice = findMustNiceSkills(tax, jdRaw)
+    return must, nice, fallback, true
 }
 
-func extractJDInfo(ctx context.Context, client *openAIClient, jdText string) (JDExtract, error) {
+func extractJDInfo(ctx context.Context, client *aiClient, jdText string) (JDExtract, error) {
     system := strings.Join([]string{
         "You extract only job-related requirements.",
         "Ignore demographics or personal details.",
@@ -474,7 +730,7 @@ func extractJDInfo(ctx context.Context, client *openAIClient, jdText string) (JD
     }, " ")
     schema := jdExtractSchema()
     var out JDExtract
-    if err := client.chatCompletionJSON(ctx, "jd_extract", schema, system, jdText, &out); err != nil {
+    if err := client.chat.ChatJSON(ctx, "jd_extract", schema, system, jdText, &out); err != nil {
         return JDExtract{}, err
     }
     out.RoleTitle = strings.TrimSpace(out.RoleTitle)
@@ -491,7 +747,7 @@ func extractJDInfo(ctx context.Context, client *openAIClient, jdText string) (JD
     return out, nil
 }
 
-func explainResume(ctx context.Context, client *openAIClient, jd JDExtract, resumeText string) (ResumeAnalysis, error) {
+func explainResume(ctx context.Context, client *aiClient, jd JDExtract, resumeText string) (ResumeAnalysis, error) {
     system := strings.Join([]string{
         "You evaluate a resume against job requirements.",
         "Focus only on job-relevant skills and experience.",
@@ -505,7 +761,7 @@ func explainResume(ctx context.Context, client *openAIClient, jd JDExtract, resu
 
     schema := resumeAnalysisSchema()
     var out ResumeAnalysis
-    if err := client.chatCompletionJSON(ctx, "resume_analysis", schema, system, user, &out); err != nil {
+    if err := client.chat.ChatJSON(ctx, "resume_analysis", schema, system, user, &out); err != nil {
         return ResumeAnalysis{}, err
     }
     out.Strengths = cleanList(out.Strengths)
@@ -520,7 +776,16 @@ func explainResume(ctx context.Context, client *openAIClient, jd JDExtract, resu
     return out, nil
 }
 
-func embedDocuments(ctx context.Context, client *openAIClient, docs []string) ([][]float64, error) {
+// fitAndEmbed fits the local embedder to docs, if that is the provider, and
+// embeds them.
+func fitAndEmbed(ctx context.Context, client *aiClient, tax *skillTaxonomy, docs []string, prog *progressReporter) ([][]float64, error) {
+    if f, ok := client.embedder.(corpusFitter); ok {
+        f.fit(tax, docs)
+    }
+    return embedDocuments(ctx, client, docs, prog)
+}
+
+func embedDocuments(ctx context.Context, client *aiClient, docs []string, prog *progressReporter) ([][]float64, error) {
     chunks := make([]string, 0)
     docChunkIdxs := make([][]int, len(docs))
     for i, doc := range docs {
@@ -534,7 +799,8 @@ func embedDocuments(ctx context.Context, client *openAIClient, docs []string) ([
     if len(chunks) == 0 {
         return make([][]float64, len(docs)), nil
     }
-    embeds, err := client.embedTexts(ctx, chunks)
+    prog.start(StageEmbed, len(chunks))
+    embeds, err := client.embedTexts(ctx, chunks, prog)
     if err != nil {
         return nil, err
     }
@@ -620,27 +886,10 @@ func cosineSimVec(a, b []float64) float64 {
     return dot / (math.Sqrt(normA) * math.Sqrt(normB))
 }
 
-func skillsInText(text string, skills []string) map[string]bool {
-    set := map[string]bool{}
-    for _, s := range skills {
-        if s == "" {
-            continue
-        }
-        if strings.Contains(text, s) {
-            set[s] = true
-        }
-    }
-    return set
-}
-
-func countMatches(set map[string]bool, list []string) int {
-    count := 0
-    for _, s := range list {
-        if set[s] {
-            count++
-        }
-    }
-    return count
+// skillsInText reports which of the given skills appear in normalized text,
+// matched on token boundaries and keyed by canonical skill name.
+func skillsInText(tax *skillTaxonomy, text string, skills []string) map[string]bool {
+    return newSkillMatcher(tax, skills).match(strings.Fields(text))
 }
 
 func cleanSkillList(items []string) []string {
@@ -819,7 +1068,14 @@ func readDocx(path string) (string, error) {
     return doc.Editable().GetContent(), nil
 }
 
-func readPdf(path string) (string, error) {
+func readPdf(path string) (text string, err error) {
+    // The PDF reader panics on some malformed files.
+    defer func() {
+        if p := recover(); p != nil {
+            text, err = "", fmt.Errorf("malformed pdf: %v", p)
+        }
+    }()
+
     f, r, err := pdf.Open(path)
     if err != nil {
         return "", err
@@ -938,6 +1194,7 @@ func extractText(path string) (string, error) {
 
 func normalizeText(text string) string {
     t := strings.ToLower(redactPII(text))
+    t = rAndDRe.ReplaceAllString(t, "rnd")
     t = nonWordRe.ReplaceAllString(t, " ")
 
     tokens := strings.Fields(t)
@@ -950,16 +1207,78 @@ func normalizeText(text string) string {
     return strings.Join(out, " ")
 }
 
-func redactPII(text string) string {
+// Redaction policies for text sent to a provider (RESUMEGPT_REDACT).
+const (
+    // RedactPII removes contact details and the words in redactTerms.
+    RedactPII     = "pii"
+    // RedactContact only removes emails and phone numbers.
+    RedactContact = "contact"
+    RedactOff     = "off"
+)
+
+func parseRedactPolicy(policy string) (string, error) {
+    policy = strings.ToLower(strings.TrimSpace(policy))
+    switch policy {
+    case RedactPII, RedactContact, RedactOff:
+        return policy, nil
+    }
+    return "", fmt.Errorf("redact policy %q (want pii, contact or off)", policy)
+}
+
+// redactForProvider applies the redaction policy to text before it is sent
+// to a provider. An unknown policy redacts everything.
+func redactForProvider(text string) string {
+    policy, _ := parseRedactPolicy(envString("RESUMEGPT_REDACT", RedactPII))
+    switch policy {
+    case RedactOff:
+        return text
+    case RedactContact:
+        return redactContact(text)
+    }
+    return redactPII(text)
+}
+
+func redactContact(text string) string {
     t := emailRe.ReplaceAllString(text, " ")
-    t = phoneRe.ReplaceAllString(t, " ")
+    return phoneRe.ReplaceAllString(t, " ")
+}
+
+func redactPII(text string) string {
+    t := redactContact(text)
     for _, term := range redactTerms {
         re := regexp.MustCompile(`(?i)\b` + regexp.QuoteMeta(term) + `\b`)
-        t = re.ReplaceAllString(t, " ")
+        prev, keep := redactKeep[term]
+        if !keep {
+            t = re.ReplaceAllString(t, " ")
+            continue
+        }
+        var b strings.Builder
+        last := 0
+        for _, m := range re.FindAllStringIndex(t, -1) {
+            if followsWord(t[:m[0]], prev) {
+                continue
+            }
+            b.WriteString(t[last:m[0]])
+            b.WriteString(" ")
+            last = m[1]
+        }
+        b.WriteString(t[last:])
+        t = b.String()
     }
     return t
 }
 
+// followsWord reports whether text ends with word, ignoring the spaces and
+// hyphens after it.
+func followsWord(text, word string) bool {
+    text = strings.ToLower(strings.TrimRight(text, " \t-"))
+    if !strings.HasSuffix(text, word) {
+        return false
+    }
+    rest := text[:len(text)-len(word)]
+    return rest == "" || !unicode.IsLetter(rune(rest[len(rest)-1])) && !unicode.IsDigit(rune(rest[len(rest)-1]))
+}
+
 func buildNgrams(tokens []string, n int) []string {
     if n <= 1 {
         return tokens
@@ -1073,16 +1392,16 @@ func topTerms(text string, maxTerms int) []string {
     return out
 }
 
-func extractSkills(text string, jdTerms []string) []string {
-    skills := map[string]bool{}
-    for _, s := range skillLexicon {
-        if strings.Contains(text, s) {
-            skills[s] = true
-        }
+func extractSkills(tax *skillTaxonomy, text string, jdTerms []string) []string {
+    tokens := strings.Fields(text)
+    skills := tax.matcher.match(tokens)
+    present := map[string]bool{}
+    for _, tok := range tokens {
+        present[tok] = true
     }
     for _, t := range jdTerms {
-        if len(t) >= 3 && strings.Contains(text, t) {
-            skills[t] = true
+        if len(t) >= 3 && present[t] {
+            skills[tax.canonical(t)] = true
         }
     }
     out := make([]string, 0, len(skills))
@@ -1093,7 +1412,7 @@ func extractSkills(text string, jdTerms []string) []string {
     return out
 }
 
-func findMustNiceSkills(jdRaw string) (must []string, nice []string) {
+func findMustNiceSkills(tax *skillTaxonomy, jdRaw string) (must []string, nice []string) {
     lines := strings.Split(strings.ToLower(jdRaw), "\n")
     mustSet := map[string]bool{}
     niceSet := map[string]bool{}
@@ -1123,14 +1442,12 @@ func findMustNiceSkills(jdRaw string) (must []string, nice []string) {
         if !isMust && !isNice {
             continue
         }
-        for _, s := range skillLexicon {
-            if strings.Contains(line, s) {
-                if isMust {
-                    mustSet[s] = true
-                }
-                if isNice {
-                    niceSet[s] = true
-                }
+        for s := range tax.matcher.match(skillTokens(line)) {
+            if isMust {
+                mustSet[s] = true
+            }
+            if isNice {
+                niceSet[s] = true
             }
         }
     }
@@ -1146,20 +1463,6 @@ func findMustNiceSkills(jdRaw string) (must []string, nice []string) {
     return must, nice
 }
 
-func scoreWeights(mustCount int) (float64, float64, float64, float64) {
-    wCos := 0.45
-    wMust := 0.35
-    wNice := 0.10
-    wSkill := 0.10
-    if mustCount == 0 {
-        wCos = 0.55
-        wNice = 0.15
-        wSkill = 0.30
-        wMust = 0.0
-    }
-    return wCos, wMust, wNice, wSkill
-}
-
 func listResumeFiles(dir string) ([]string, error) {
     files := []string{}
     err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
@@ -1200,6 +1503,72 @@ func hexVal(b byte) (byte, bool) {
     }
 }
 
+// writeOutputs writes the top N results and the skipped report and saves the
+// run to the history. out holds the full ranking, which the history keeps so
+// that runs can be compared beyond the top N; the returned Output is cut.
+func writeOutputs(ctx context.Context, input Input, out Output, resumeFiles []string) (Output, error) {
+    ranked := out.Results
+    if input.TopN > 0 && len(out.Results) > input.TopN {
+        out.Results = out.Results[:input.TopN]
+    }
+    outPath := resultsPath(input)
+    formats, err := outputFormats(input.Formats, outPath)
+    if err != nil {
+        return Output{}, err
+    }
+    out.Files = make(map[string]string, len(formats))
+    for _, f := range formats {
+        out.Files[f] = formatPath(outPath, f)
+    }
+    out.OutPath = out.Files[formats[0]]
+    report, _ := normalizeReport(input.Report)
+    if report != "" {
+        out.Files[report] = formatPath(outPath, report)
+    }
+
+    doc := newResultsDocument(input, out)
+    for _, f := range formats {
+        var err error
+        switch f {
+        case FormatCSV:
+            err = writeResultsCSV(out.Files[f], out.Results)
+        case FormatJSON:
+            err = writeResultsJSON(out.Files[f], doc)
+        case FormatJSONL:
+            err = writeResultsJSONL(out.Files[f], doc)
+        case FormatXLSX:
+            err = writeResultsXLSX(out.Files[f], doc)
+        }
+        if err != nil {
+            return Output{}, fmt.Errorf("%w: %v", ErrWriteResults, err)
+        }
+    }
+    if report == ReportHTML {
+        if err := writeReportHTML(out.Files[report], doc); err != nil {
+            return Output{}, fmt.Errorf("%w: %v", ErrWriteResults, err)
+        }
+    }
+    if input.ResultsWorkbook != "" {
+        if err := updateResultsWorkbook(input.ResultsWorkbook, doc); err != nil {
+            return Output{}, fmt.Errorf("%w: %s: %v", ErrWriteResults, input.ResultsWorkbook, err)
+        }
+    }
+    if err := writeSkippedCSV(skippedPath(out.OutPath), out.Skipped); err != nil {
+        return Output{}, fmt.Errorf("%w: %v", ErrWriteResults, err)
+    }
+    // The results files are already written, so a history failure is only
+    // logged.
+    if out.RunID != "" {
+        doc.Output = out
+        doc.Output.Results = ranked
+        if err := saveRun(input, doc, resumeFiles); err != nil {
+            out.RunID = ""
+            logFrom(ctx).Warn("run not saved to history", "error", err)
+        }
+    }
+    return out, nil
+}
+
 func writeResultsCSV(path string, results []Result) error {
     if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
         return err
@@ -1212,9 +1581,9 @@ func writeResultsCSV(path string, results []Result) error {
     defer f.Close()
 
     w := csv.NewWriter(f)
-    _ = w.Write([]string{"Rank", "Candidate", "Score", "Strengths", "Weaknesses", "Explanation", "File"})
+    _ = w.Write(resultColumns())
     for _, r := range results {
-        _ = w.Write([]string{
+        row := []string{
             fmt.Sprintf("%d", r.Rank),
             r.Candidate,
             fmt.Sprintf("%.2f", r.Score),
@@ -1222,22 +1591,19 @@ func writeResultsCSV(path string, results []Result) error {
             r.Weaknesses,
             r.Explanation,
             r.File,
-        })
+        }
+        row = append(row, r.Breakdown.csvRow()...)
+        _ = w.Write(append(row, fmt.Sprintf("%t", r.KnockedOut), strings.Join(r.FailedRules, "; "), r.Warning))
     }
     w.Flush()
     return w.Error()
 }
 
-func appendLog(outPath string, total int) {
-    logPath := filepath.Join(filepath.Dir(outPath), "run_log.txt")
-    f, err := os.OpenFile(logPath, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
-    if err != nil {
-        return
-    }
-    defer f.Close()
-    w := bufio.NewWriter(f)
-    fmt.Fprintf(w, "%s | Scored %d resumes | %s\n", time.Now().Format(time.RFC3339), total, outPath)
-    _ = w.Flush()
+// resultColumns is the header row of results.csv and the XLSX Results sheet.
+func resultColumns() []string {
+    header := []string{"Rank", "Candidate", "Score", "Strengths", "Weaknesses", "Explanation", "File"}
+    header = append(header, breakdownColumns[:]...)
+    return append(header, "Knocked Out", "Failed Rules", "Warning")
 }
 
 func fileExists(path string) bool {
@@ -1250,11 +1616,11 @@ func dirExists(path string) bool {
     return err == nil && fi.IsDir()
 }
 
-func ratio(a, b int) float64 {
+func ratio(a float64, b int) float64 {
     if b == 0 {
         return 0
     }
-    return float64(a) / float64(b)
+    return a / float64(b)
 }
 
 func round(v float64) float64 {
@@ -1304,6 +1670,22 @@ func buildWeaknesses(resSkills map[string]bool, must, nice, general []string) []
     return weaknesses
 }
 
+func sortedKeys(set map[string]bool) []string {
+    out := make([]string, 0, len(set))
+    for k := range set {
+        out = append(out, k)
+    }
+    sort.Strings(out)
+    return out
+}
+
+func joinWarnings(a, b string) string {
+    if a == "" {
+        return b
+    }
+    return a + "; " + b
+}
+
 func contains(list []string, item string) bool {
     for _, v := range list {
         if v == item {
@@ -1311,4 +1693,4 @@ func contains(list []string, item string) bool {
         }
     }
     return false
-}
+}
@@ -0,0 +1,306 @@
+package matcher
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// MatrixSchemaVersion is the version of matrix.json described in
+// docs/results-schema.md, bumped like ResultsSchemaVersion.
+const (
+	MatrixSchema        = "resumegpt.matrix"
+	MatrixSchemaVersion = 1
+)
+
+// Sheets of the matrix workbook.
+const (
+	matrixSheet  = "Matrix"
+	bestFitSheet = "Best Fit"
+	jobsSheet    = "Jobs"
+)
+
+// MatrixDocument is the content of matrix.json: the whole BatchOutput plus
+// the schema version and the run's inputs.
+type MatrixDocument struct {
+	Schema        string    `json:"schema"`
+	SchemaVersion int       `json:"schemaVersion"`
+	GeneratedAt   time.Time `json:"generatedAt"`
+	ResumesDir    string    `json:"resumesDir"`
+	TopN          int       `json:"topN"`
+	BatchOutput
+}
+
+// matrixColumns heads matrix.csv: one score column per job between the
+// candidate and their best fit.
+func matrixColumns(jobs []BatchJob) []string {
+	cols := []string{"Candidate", "File"}
+	for _, j := range jobs {
+		cols = append(cols, j.Name)
+	}
+	return append(cols, "Best Job", "Best Score", "Knocked Out Jobs")
+}
+
+// matrixRow is a candidate's matrix.csv row. Excluded cells are empty.
+func matrixRow(c CandidateFit) []string {
+	row := []string{c.Candidate, c.File}
+	var knockedOut []string
+	for _, s := range c.Scores {
+		if s.Excluded {
+			row = append(row, "")
+		} else {
+			row = append(row, fmt.Sprintf("%.2f", s.Score))
+		}
+		if s.KnockedOut {
+			knockedOut = append(knockedOut, s.Job)
+		}
+	}
+	best, score := "", ""
+	if len(c.BestFit) > 0 {
+		best, score = c.BestFit[0].Job, fmt.Sprintf("%.2f", c.BestFit[0].Score)
+	}
+	return append(row, best, score, strings.Join(knockedOut, "; "))
+}
+
+func writeMatrixCSV(path string, batch BatchOutput) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	w := csv.NewWriter(f)
+	_ = w.Write(matrixColumns(batch.Jobs))
+	for _, c := range batch.Candidates {
+		_ = w.Write(matrixRow(c))
+	}
+	w.Flush()
+	return w.Error()
+}
+
+func writeMatrixJSON(path string, doc MatrixDocument) error {
+	data, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0644)
+}
+
+func bestFitColumns() []string {
+	return []string{"Candidate", "File", "Fit", "Job", "Score", "Job Rank"}
+}
+
+// writeBestFitCSV lists each candidate's best-fit jobs, best first.
+func writeBestFitCSV(path string, batch BatchOutput) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	w := csv.NewWriter(f)
+	_ = w.Write(bestFitColumns())
+	for _, c := range batch.Candidates {
+		for i, s := range c.BestFit {
+			_ = w.Write([]string{c.Candidate, c.File, fmt.Sprintf("%d", i+1), s.Job, fmt.Sprintf("%.2f", s.Score), fmt.Sprintf("%d", s.Rank)})
+		}
+	}
+	w.Flush()
+	return w.Error()
+}
+
+// writeMatrixXLSX writes a workbook with the Matrix sheet (a color scale on
+// the scores, knocked out cells grayed out), the Best Fit list and a Jobs
+// sheet with each JD's requirements and result files.
+func writeMatrixXLSX(path string, doc MatrixDocument) error {
+	f := excelize.NewFile()
+	defer f.Close()
+	if err := f.SetSheetName("Sheet1", matrixSheet); err != nil {
+		return err
+	}
+	for _, sheet := range []string{bestFitSheet, jobsSheet} {
+		if _, err := f.NewSheet(sheet); err != nil {
+			return err
+		}
+	}
+	header, err := f.NewStyle(&excelize.Style{
+		Font:   &excelize.Font{Bold: true},
+		Fill:   excelize.Fill{Type: "pattern", Pattern: 1, Color: []string{"D9E1F2"}},
+		Border: []excelize.Border{{Type: "bottom", Color: "8EA9DB", Style: 1}},
+	})
+	if err != nil {
+		return err
+	}
+	points, err := f.NewStyle(&excelize.Style{NumFmt: 2})
+	if err != nil {
+		return err
+	}
+	knockedOut, err := f.NewStyle(&excelize.Style{NumFmt: 2, Font: &excelize.Font{Color: "808080", Italic: true}})
+	if err != nil {
+		return err
+	}
+
+	// Matrix: candidates down, jobs across.
+	cols := matrixColumns(doc.Jobs)
+	if err := writeSheetHeader(f, matrixSheet, cols, header, len(doc.Candidates)); err != nil {
+		return err
+	}
+	firstJob, lastJob := 3, 2+len(doc.Jobs)
+	for i, c := range doc.Candidates {
+		row := []any{c.Candidate, c.File}
+		for _, s := range c.Scores {
+			if s.Excluded {
+				row = append(row, nil)
+			} else {
+				row = append(row, s.Score)
+			}
+		}
+		best, score := "", any(nil)
+		if len(c.BestFit) > 0 {
+			best, score = c.BestFit[0].Job, c.BestFit[0].Score
+		}
+		var koJobs []string
+		for _, s := range c.Scores {
+			if s.KnockedOut {
+				koJobs = append(koJobs, s.Job)
+			}
+		}
+		row = append(row, best, score, strings.Join(koJobs, "; "))
+		cell, _ := excelize.CoordinatesToCellName(1, i+2)
+		if err := f.SetSheetRow(matrixSheet, cell, &row); err != nil {
+			return err
+		}
+		for j, s := range c.Scores {
+			cell, _ := excelize.CoordinatesToCellName(firstJob+j, i+2)
+			style := points
+			if s.KnockedOut {
+				style = knockedOut
+			}
+			if err := f.SetCellStyle(matrixSheet, cell, cell, style); err != nil {
+				return err
+			}
+		}
+		bestCell, _ := excelize.CoordinatesToCellName(lastJob+2, i+2)
+		if err := f.SetCellStyle(matrixSheet, bestCell, bestCell, points); err != nil {
+			return err
+		}
+	}
+	if len(doc.Candidates) > 0 && len(doc.Jobs) > 0 {
+		top, _ := excelize.CoordinatesToCellName(firstJob, 2)
+		bottom, _ := excelize.CoordinatesToCellName(lastJob, len(doc.Candidates)+1)
+		if err := f.SetConditionalFormat(matrixSheet, top+":"+bottom, []excelize.ConditionalFormatOptions{{
+			Type:     "3_color_scale",
+			Criteria: "=",
+			MinType:  "num",
+			MidType:  "num",
+			MaxType:  "num",
+			MinValue: "0",
+			MidValue: "50",
+			MaxValue: "100",
+			MinColor: "#F8696B",
+			MidColor: "#FFEB84",
+			MaxColor: "#63BE7B",
+		}}); err != nil {
+			return err
+		}
+	}
+	if err := f.SetPanes(matrixSheet, &excelize.Panes{
+		Freeze:      true,
+		XSplit:      1,
+		YSplit:      1,
+		TopLeftCell: "B2",
+		ActivePane:  "bottomRight",
+	}); err != nil {
+		return err
+	}
+	if err := setColWidths(f, matrixSheet, len(cols), map[int]float64{1: 28, 2: 40, lastJob + 1: 24, lastJob + 3: 30}, 14); err != nil {
+		return err
+	}
+
+	// Best Fit: one row per candidate and job.
+	fits := 0
+	for _, c := range doc.Candidates {
+		fits += len(c.BestFit)
+	}
+	if err := writeSheetHeader(f, bestFitSheet, bestFitColumns(), header, fits); err != nil {
+		return err
+	}
+	r := 2
+	for _, c := range doc.Candidates {
+		for i, s := range c.BestFit {
+			row := []any{c.Candidate, c.File, i + 1, s.Job, s.Score, s.Rank}
+			cell, _ := excelize.CoordinatesToCellName(1, r)
+			if err := f.SetSheetRow(bestFitSheet, cell, &row); err != nil {
+				return err
+			}
+			scoreCell, _ := excelize.CoordinatesToCellName(5, r)
+			if err := f.SetCellStyle(bestFitSheet, scoreCell, scoreCell, points); err != nil {
+				return err
+			}
+			r++
+		}
+	}
+	if err := setColWidths(f, bestFitSheet, 6, map[int]float64{1: 28, 2: 40, 4: 28}, 10); err != nil {
+		return err
+	}
+
+	// Jobs: what each JD asks for and where its top N went.
+	jobCols := []string{"Job", "Job Description", "Role Title", "Must-Have Skills", "Nice-to-Have Skills", "Minimum Years", "Ranked", "Excluded", "Results"}
+	if err := writeSheetHeader(f, jobsSheet, jobCols, header, len(doc.Jobs)); err != nil {
+		return err
+	}
+	for i, j := range doc.Jobs {
+		row := []any{j.Name, j.JDPath, "", "", "", nil, j.Ranked, j.Excluded, j.OutPath}
+		if jd := j.JDInfo; jd != nil {
+			row[2], row[3], row[4], row[5] = jd.RoleTitle, strings.Join(jd.SkillsMust, ", "), strings.Join(jd.SkillsNice, ", "), jd.YearsExperienceMin
+		}
+		cell, _ := excelize.CoordinatesToCellName(1, i+2)
+		if err := f.SetSheetRow(jobsSheet, cell, &row); err != nil {
+			return err
+		}
+	}
+	if err := setColWidths(f, jobsSheet, len(jobCols), map[int]float64{1: 24, 2: 40, 3: 28, 4: 40, 5: 40, 9: 40}, 12); err != nil {
+		return err
+	}
+
+	f.SetActiveSheet(0)
+	return f.SaveAs(path)
+}
+
+// writeSheetHeader writes a bold, frozen header row with an autofilter over
+// the rows below it.
+func writeSheetHeader(f *excelize.File, sheet string, cols []string, style, rows int) error {
+	if err := f.SetSheetRow(sheet, "A1", &cols); err != nil {
+		return err
+	}
+	last, _ := excelize.CoordinatesToCellName(len(cols), 1)
+	if err := f.SetCellStyle(sheet, "A1", last, style); err != nil {
+		return err
+	}
+	if sheet != matrixSheet {
+		if err := f.SetPanes(sheet, &excelize.Panes{Freeze: true, YSplit: 1, TopLeftCell: "A2", ActivePane: "bottomLeft"}); err != nil {
+			return err
+		}
+	}
+	bottom, _ := excelize.CoordinatesToCellName(len(cols), rows+1)
+	return f.AutoFilter(sheet, "A1:"+bottom, nil)
+}
+
+func setColWidths(f *excelize.File, sheet string, cols int, widths map[int]float64, def float64) error {
+	for c := 1; c <= cols; c++ {
+		name, _ := excelize.ColumnNumberToName(c)
+		width, ok := widths[c]
+		if !ok {
+			width = def
+		}
+		if err := f.SetColWidth(sheet, name, name, width); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+package matcher
+
+import (
+	"context"
+	"errors"
+)
+
+// ollamaProvider talks to a local Ollama server through its native /api/chat
+// and /api/embed endpoints, so matching can run fully offline. Ollama needs no
+// API key; structured output is requested by passing the schema as format.
+type ollamaProvider struct {
+	api         *apiTransport
+	llmModel    string
+	embedModel  string
+	temperature float64
+	usage       *usageMeter
+}
+
+type ollamaChatRequest struct {
+	Model    string         `json:"model"`
+	Messages []chatMessage  `json:"messages"`
+	Format   map[string]any `json:"format,omitempty"`
+	Stream   bool           `json:"stream"`
+	Options  map[string]any `json:"options,omitempty"`
+}
+
+type ollamaChatResponse struct {
+	Message         chatMessage `json:"message"`
+	PromptEvalCount int         `json:"prompt_eval_count"`
+	EvalCount       int         `json:"eval_count"`
+}
+
+type ollamaEmbedRequest struct {
+	Model string   `json:"model"`
+	Input []string `json:"input"`
+}
+
+type ollamaEmbedResponse struct {
+	Embeddings      [][]float64 `json:"embeddings"`
+	PromptEvalCount int         `json:"prompt_eval_count"`
+}
+
+func (p *ollamaProvider) ChatJSON(ctx context.Context, schemaName string, schema map[string]any, system, user string, out any) error {
+	// Smaller local models follow the format constraint more reliably when
+	// the schema is also spelled out in the prompt.
+	req := ollamaChatRequest{
+		Model: p.llmModel,
+		Messages: []chatMessage{
+			{Role: "system", Content: withSchemaPrompt(system, schema)},
+			{Role: "user", Content: user},
+		},
+		Format:  schema,
+		Options: map[string]any{"temperature": p.temperature},
+	}
+	var resp ollamaChatResponse
+	if err := p.api.doJSON(ctx, "/api/chat", req, &resp); err != nil {
+		return err
+	}
+	p.usage.addChat(p.llmModel, resp.PromptEvalCount, resp.EvalCount)
+	return decodeChatJSON("ollama", resp.Message.Content, out)
+}
+
+func (p *ollamaProvider) Embed(ctx context.Context, texts []string) ([][]float64, error) {
+	req := ollamaEmbedRequest{
+		Model: p.embedModel,
+		Input: texts,
+	}
+	var resp ollamaEmbedResponse
+	if err := p.api.doJSON(ctx, "/api/embed", req, &resp); err != nil {
+		return nil, err
+	}
+	p.usage.addEmbedding(p.embedModel, resp.PromptEvalCount)
+	if len(resp.Embeddings) != len(texts) {
+		return nil, errors.New("ollama: embedding count does not match input")
+	}
+	return resp.Embeddings, nil
+}
@@ -4,72 +4,43 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
-	"errors"
 	"fmt"
 	"io"
 	"net/http"
-	"os"
 	"strconv"
 	"strings"
 	"time"
 )
 
-type openAIClient struct {
-	apiKey          string
-	baseURL         string
-	httpClient      *http.Client
-	embedModel      string
-	llmModel        string
-	explainTopN     int
-	embedBatchSize  int
-	embedChunkWords int
-	explainMaxChars int
-	temperature     float64
+// openAIProvider talks to the OpenAI API and to servers with the same
+// /chat/completions and /embeddings shapes (Azure OpenAI, vLLM, LM Studio).
+type openAIProvider struct {
+	// name is the provider the client was built for, used in its errors.
+	name        string
+	api         *apiTransport
+	chatPath    string
+	embedPath   string
+	llmModel    string
+	embedModel  string
+	temperature float64
+	// strictSchema asks for a json_schema response format. Servers that only
+	// support json_object get the schema in the system prompt instead.
+	strictSchema bool
+	usage        *usageMeter
 }
 
-func newOpenAIClientFromEnv() (*openAIClient, error) {
-	apiKey := strings.TrimSpace(os.Getenv("OPENAI_API_KEY"))
-	if apiKey == "" {
-		return nil, ErrMissingOpenAIKey
-	}
-
-	baseURL := strings.TrimSpace(os.Getenv("OPENAI_BASE_URL"))
-	if baseURL == "" {
-		baseURL = "https://api.openai.com/v1"
-	}
-
-	embedModel := strings.TrimSpace(os.Getenv("RESUMEGPT_EMBED_MODEL"))
-	if embedModel == "" {
-		embedModel = "text-embedding-3-large"
-	}
-
-	llmModel := strings.TrimSpace(os.Getenv("RESUMEGPT_LLM_MODEL"))
-	if llmModel == "" {
-		llmModel = "gpt-4o"
-	}
-
-	explainTopN := envInt("RESUMEGPT_EXPLAIN_TOPN", 20)
-	embedBatch := envInt("RESUMEGPT_EMBED_BATCH", 96)
-	embedChunkWords := envInt("RESUMEGPT_EMBED_CHUNK_WORDS", 2000)
-	explainMaxChars := envInt("RESUMEGPT_EXPLAIN_MAX_CHARS", 12000)
-	temperature := envFloat("RESUMEGPT_LLM_TEMPERATURE", 0.2)
-
-	return &openAIClient{
-		apiKey:          apiKey,
-		baseURL:         strings.TrimRight(baseURL, "/"),
-		httpClient:      &http.Client{Timeout: 90 * time.Second},
-		embedModel:      embedModel,
-		llmModel:        llmModel,
-		explainTopN:     explainTopN,
-		embedBatchSize:  max(1, embedBatch),
-		embedChunkWords: max(500, embedChunkWords),
-		explainMaxChars: max(2000, explainMaxChars),
-		temperature:     clamp(temperature, 0, 1),
-	}, nil
+// apiTransport posts JSON to one base URL, pacing requests with limiter and
+// retrying rate limits and outages according to retry.
+type apiTransport struct {
+	baseURL    string
+	headers    map[string]string
+	httpClient *http.Client
+	limiter    *rateLimiter
+	retry      retryPolicy
 }
 
 func envInt(key string, def int) int {
-	raw := strings.TrimSpace(os.Getenv(key))
+	raw := strings.TrimSpace(Getenv(key))
 	if raw == "" {
 		return def
 	}
@@ -81,7 +52,7 @@ func envInt(key string, def int) int {
 }
 
 func envFloat(key string, def float64) float64 {
-	raw := strings.TrimSpace(os.Getenv(key))
+	raw := strings.TrimSpace(Getenv(key))
 	if raw == "" {
 		return def
 	}
@@ -93,7 +64,7 @@ func envFloat(key string, def float64) float64 {
 }
 
 func envBool(key string, def bool) bool {
-	raw := strings.TrimSpace(os.Getenv(key))
+	raw := strings.TrimSpace(Getenv(key))
 	if raw == "" {
 		return def
 	}
@@ -107,13 +78,6 @@ func envBool(key string, def bool) bool {
 	}
 }
 
-func max(a, b int) int {
-	if a > b {
-		return a
-	}
-	return b
-}
-
 func clamp(v, min, max float64) float64 {
 	if v < min {
 		return min
@@ -124,109 +88,151 @@ func clamp(v, min, max float64) float64 {
 	return v
 }
 
-func (c *openAIClient) chatCompletionJSON(ctx context.Context, schemaName string, schema map[string]any, system, user string, out any) error {
+func (p *openAIProvider) ChatJSON(ctx context.Context, schemaName string, schema map[string]any, system, user string, out any) error {
+	format := &responseFormat{
+		Type: "json_schema",
+		JSONSchema: &jsonSchema{
+			Name:        schemaName,
+			Description: "Return only valid JSON for the schema.",
+			Schema:      schema,
+			Strict:      true,
+		},
+	}
+	if !p.strictSchema {
+		format = &responseFormat{Type: "json_object"}
+		system = withSchemaPrompt(system, schema)
+	}
 	req := chatCompletionRequest{
-		Model: c.llmModel,
+		Model: p.llmModel,
 		Messages: []chatMessage{
 			{Role: "system", Content: system},
 			{Role: "user", Content: user},
 		},
-		ResponseFormat: &responseFormat{
-			Type: "json_schema",
-			JSONSchema: &jsonSchema{
-				Name:        schemaName,
-				Description: "Return only valid JSON for the schema.",
-				Schema:      schema,
-				Strict:      true,
-			},
-		},
-		Temperature: c.temperature,
+		ResponseFormat: format,
+		Temperature:    p.temperature,
 	}
 
 	var resp chatCompletionResponse
-	if err := c.doJSON(ctx, "/chat/completions", req, &resp); err != nil {
+	if err := p.api.doJSON(ctx, p.chatPath, req, &resp); err != nil {
 		return err
 	}
+	p.usage.addChat(firstNonEmpty(resp.Model, p.llmModel), resp.Usage.PromptTokens, resp.Usage.CompletionTokens)
 	if len(resp.Choices) == 0 {
-		return errors.New("openai: empty response")
+		return fmt.Errorf("%s: empty response", p.name)
 	}
 	msg := resp.Choices[0].Message
 	if strings.TrimSpace(msg.Refusal) != "" {
-		return fmt.Errorf("openai refusal: %s", msg.Refusal)
+		return fmt.Errorf("%s refusal: %s", p.name, msg.Refusal)
 	}
-	content := strings.TrimSpace(msg.Content)
+	return decodeChatJSON(p.name, msg.Content, out)
+}
+
+func (p *openAIProvider) Embed(ctx context.Context, texts []string) ([][]float64, error) {
+	req := embeddingsRequest{
+		Model: p.embedModel,
+		Input: texts,
+	}
+	var resp embeddingsResponse
+	if err := p.api.doJSON(ctx, p.embedPath, req, &resp); err != nil {
+		return nil, err
+	}
+	p.usage.addEmbedding(firstNonEmpty(resp.Model, p.embedModel), resp.Usage.PromptTokens)
+	out := make([][]float64, len(texts))
+	for _, item := range resp.Data {
+		if item.Index < 0 || item.Index >= len(texts) {
+			continue
+		}
+		out[item.Index] = item.Embedding
+	}
+	return out, nil
+}
+
+// withSchemaPrompt appends the schema to a system prompt for models that
+// cannot be constrained to it.
+func withSchemaPrompt(system string, schema map[string]any) string {
+	raw, _ := json.Marshal(schema)
+	return system + "\nRespond with a single JSON object matching this JSON schema:\n" + string(raw)
+}
+
+func decodeChatJSON(provider, content string, out any) error {
+	content = strings.TrimSpace(content)
 	if content == "" {
-		return errors.New("openai: empty content")
+		return fmt.Errorf("%s: empty content", provider)
 	}
 	if err := json.Unmarshal([]byte(content), out); err != nil {
-		return fmt.Errorf("openai: invalid json: %w", err)
+		return fmt.Errorf("%s: invalid json: %w", provider, err)
 	}
 	return nil
 }
 
-func (c *openAIClient) embedTexts(ctx context.Context, texts []string) ([][]float64, error) {
-	cleaned := make([]string, 0, len(texts))
-	for _, t := range texts {
-		trimmed := strings.TrimSpace(t)
-		if trimmed == "" {
-			trimmed = "empty"
-		}
-		cleaned = append(cleaned, trimmed)
+func (t *apiTransport) doJSON(ctx context.Context, path string, reqBody any, respBody any) error {
+	body, err := json.Marshal(reqBody)
+	if err != nil {
+		return err
 	}
 
-	out := make([][]float64, len(cleaned))
-	for i := 0; i < len(cleaned); i += c.embedBatchSize {
-		end := i + c.embedBatchSize
-		if end > len(cleaned) {
-			end = len(cleaned)
+	log := logFrom(ctx)
+	for attempt := 0; ; attempt++ {
+		status, took, apiErr := t.doJSONOnce(ctx, path, body, respBody)
+		attrs := []any{"url", t.baseURL + path, "status", status, "attempt", attempt + 1, "duration_ms", durationMS(took)}
+		if apiErr == nil {
+			log.Info("api call", attrs...)
+			return nil
 		}
-		req := embeddingsRequest{
-			Model: c.embedModel,
-			Input: cleaned[i:end],
+		apiErr.Attempts = attempt + 1
+		if ctx.Err() != nil {
+			return ctx.Err()
 		}
-		var resp embeddingsResponse
-		if err := c.doJSON(ctx, "/embeddings", req, &resp); err != nil {
-			return nil, err
+		attrs = append(attrs, "error", apiErr.Message)
+		if !apiErr.retryable() || attempt >= t.retry.maxRetries {
+			log.Error("api call failed", attrs...)
+			return apiErr
 		}
-		for _, item := range resp.Data {
-			idx := i + item.Index
-			if idx >= 0 && idx < len(out) {
-				out[idx] = item.Embedding
-			}
+		log.Warn("api call failed, retrying", attrs...)
+		if err := t.retry.sleep(ctx, t.retry.backoff(attempt, apiErr.RetryAfter)); err != nil {
+			return err
 		}
 	}
-	return out, nil
 }
 
-func (c *openAIClient) doJSON(ctx context.Context, path string, reqBody any, respBody any) error {
-	body, err := json.Marshal(reqBody)
-	if err != nil {
-		return err
+// doJSONOnce makes a single request and returns its HTTP status (0 when no
+// response came back) and latency, not counting the rate limiter's wait.
+// Transport failures and timeouts are reported as ErrProviderUnavailable so
+// they are retried like 5xx responses.
+func (t *apiTransport) doJSONOnce(ctx context.Context, path string, body []byte, respBody any) (int, time.Duration, *APIError) {
+	if err := t.limiter.wait(ctx); err != nil {
+		return 0, 0, &APIError{Kind: ErrProviderUnavailable, Message: err.Error()}
 	}
-	url := c.baseURL + path
+	started := time.Now()
+	url := t.baseURL + path
 	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
 	if err != nil {
-		return err
+		return 0, 0, &APIError{Kind: ErrProviderBadRequest, Message: err.Error()}
+	}
+	for k, v := range t.headers {
+		req.Header.Set(k, v)
 	}
-	req.Header.Set("Authorization", "Bearer "+c.apiKey)
 	req.Header.Set("Content-Type", "application/json")
 
-	resp, err := c.httpClient.Do(req)
+	resp, err := t.httpClient.Do(req)
 	if err != nil {
-		return err
+		return 0, time.Since(started), &APIError{Kind: ErrProviderUnavailable, Message: err.Error()}
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
 		raw, _ := io.ReadAll(resp.Body)
-		var apiErr openAIErrorResponse
-		if json.Unmarshal(raw, &apiErr) == nil && apiErr.Error.Message != "" {
-			return fmt.Errorf("openai: %s (%s)", apiErr.Error.Message, apiErr.Error.Type)
+		var errBody openAIErrorResponse
+		if json.Unmarshal(raw, &errBody) != nil || errBody.Error.Message == "" {
+			errBody.Error.Message = strings.TrimSpace(string(raw))
 		}
-		return fmt.Errorf("openai: http %d: %s", resp.StatusCode, strings.TrimSpace(string(raw)))
+		return resp.StatusCode, time.Since(started), classifyResponse(resp, errBody)
 	}
 
-	return json.NewDecoder(resp.Body).Decode(respBody)
+	if err := json.NewDecoder(resp.Body).Decode(respBody); err != nil {
+		return resp.StatusCode, time.Since(started), &APIError{Kind: ErrProviderUnavailable, StatusCode: resp.StatusCode, Message: "invalid response: " + err.Error()}
+	}
+	return resp.StatusCode, time.Since(started), nil
 }
 
 type chatCompletionRequest struct {
@@ -261,10 +267,19 @@ type jsonSchema struct {
 }
 
 type chatCompletionResponse struct {
+	Model   string `json:"model"`
 	Choices []struct {
 		Message      chatMessage `json:"message"`
 		FinishReason string      `json:"finish_reason"`
 	} `json:"choices"`
+	Usage tokenUsage `json:"usage"`
+}
+
+// tokenUsage is the usage block of chat and embedding responses; embeddings
+// only fill PromptTokens.
+type tokenUsage struct {
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
 }
 
 type embeddingsRequest struct {
@@ -273,7 +288,9 @@ type embeddingsRequest struct {
 }
 
 type embeddingsResponse struct {
-	Data []embeddingData `json:"data"`
+	Model string          `json:"model"`
+	Data  []embeddingData `json:"data"`
+	Usage tokenUsage      `json:"usage"`
 }
 
 type embeddingData struct {
@@ -285,5 +302,6 @@ type openAIErrorResponse struct {
 	Error struct {
 		Message string `json:"message"`
 		Type    string `json:"type"`
+		Code    string `json:"code"`
 	} `json:"error"`
 }
@@ -0,0 +1,175 @@
+package matcher
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Output formats selectable with Input.Formats.
+const (
+	FormatCSV   = "csv"
+	FormatJSON  = "json"
+	FormatJSONL = "jsonl"
+	FormatXLSX  = "xlsx"
+)
+
+// ResultsSchemaVersion is the version of the JSON and JSONL results files
+// described in docs/results-schema.md. It is bumped whenever a field is
+// renamed, removed or changes meaning; new fields may appear within a version.
+const (
+	ResultsSchema        = "resumegpt.results"
+	ResultsSchemaVersion = 1
+)
+
+var ErrFormat = errors.New("unknown output format")
+
+// ResultsDocument is the content of results.json: the whole Output plus the
+// schema version and the run's inputs.
+type ResultsDocument struct {
+	Schema        string    `json:"schema"`
+	SchemaVersion int       `json:"schemaVersion"`
+	GeneratedAt   time.Time `json:"generatedAt"`
+	JDPath        string    `json:"jdPath"`
+	ResumesDir    string    `json:"resumesDir"`
+	TopN          int       `json:"topN"`
+	Output
+}
+
+// ParseFormats splits a comma-separated format list such as "csv,json".
+func ParseFormats(list string) ([]string, error) {
+	if strings.TrimSpace(list) == "" {
+		return nil, nil
+	}
+	return normalizeFormats(strings.Split(list, ","))
+}
+
+func normalizeFormats(formats []string) ([]string, error) {
+	out := make([]string, 0, len(formats))
+	seen := map[string]bool{}
+	for _, f := range formats {
+		f = strings.ToLower(strings.TrimSpace(f))
+		if f == "" || seen[f] {
+			continue
+		}
+		switch f {
+		case FormatCSV, FormatJSON, FormatJSONL, FormatXLSX:
+		default:
+			return nil, fmt.Errorf("%w: %q (want csv, json, jsonl or xlsx)", ErrFormat, f)
+		}
+		seen[f] = true
+		out = append(out, f)
+	}
+	return out, nil
+}
+
+// outputFormats returns the formats to write. Without any, the extension of
+// outPath picks one and csv is the default.
+func outputFormats(formats []string, outPath string) ([]string, error) {
+	out, err := normalizeFormats(formats)
+	if err != nil || len(out) > 0 {
+		return out, err
+	}
+	switch strings.ToLower(filepath.Ext(outPath)) {
+	case ".json":
+		return []string{FormatJSON}, nil
+	case ".jsonl":
+		return []string{FormatJSONL}, nil
+	case ".xlsx":
+		return []string{FormatXLSX}, nil
+	}
+	return []string{FormatCSV}, nil
+}
+
+// formatPath swaps the extension of outPath for the format's, so
+// outputs/results.csv also gives outputs/results.json.
+func formatPath(outPath, format string) string {
+	return strings.TrimSuffix(outPath, filepath.Ext(outPath)) + "." + format
+}
+
+func newResultsDocument(input Input, out Output) ResultsDocument {
+	return ResultsDocument{
+		Schema:        ResultsSchema,
+		SchemaVersion: ResultsSchemaVersion,
+		GeneratedAt:   time.Now().UTC().Truncate(time.Second),
+		JDPath:        input.JDPath,
+		ResumesDir:    input.ResumesDir,
+		TopN:          input.TopN,
+		Output:        out,
+	}
+}
+
+func writeResultsJSON(path string, doc ResultsDocument) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0644)
+}
+
+// writeResultsJSONL writes one "run" record with the document minus its
+// results and skipped files, then one "result" record per candidate in rank
+// order and one "skipped" record per unread file.
+func writeResultsJSONL(path string, doc ResultsDocument) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	w := bufio.NewWriter(f)
+	if err := encodeResultsJSONL(w, doc); err != nil {
+		f.Close()
+		return err
+	}
+	if err := w.Flush(); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func encodeResultsJSONL(w io.Writer, doc ResultsDocument) error {
+	enc := json.NewEncoder(w)
+
+	// The nil Results and Skipped fields shadow the document's, so the run
+	// record leaves them out.
+	run := struct {
+		Type string `json:"type"`
+		ResultsDocument
+		Results []Result      `json:"results,omitempty"`
+		Skipped []SkippedFile `json:"skipped,omitempty"`
+	}{Type: "run", ResultsDocument: doc}
+	if err := enc.Encode(run); err != nil {
+		return err
+	}
+	for _, r := range doc.Results {
+		rec := struct {
+			Type string `json:"type"`
+			Result
+		}{"result", r}
+		if err := enc.Encode(rec); err != nil {
+			return err
+		}
+	}
+	for _, sk := range doc.Skipped {
+		rec := struct {
+			Type string `json:"type"`
+			SkippedFile
+		}{"skipped", sk}
+		if err := enc.Encode(rec); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,227 @@
+package matcher
+
+import (
+	_ "embed"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+//go:embed profiles/default.yaml
+var defaultProfilesData []byte
+
+const DefaultProfile = "default"
+
+var (
+	ErrLoadProfiles   = errors.New("failed to load scoring profiles")
+	ErrUnknownProfile = errors.New("unknown scoring profile")
+	ErrInvalidWeights = errors.New("invalid scoring weights")
+)
+
+// Weights sets how much each score component counts toward the final score.
+type Weights struct {
+	Similarity float64 `yaml:"similarity" json:"similarity"`
+	Must       float64 `yaml:"must" json:"must"`
+	Nice       float64 `yaml:"nice" json:"nice"`
+	Skills     float64 `yaml:"skills" json:"skills"`
+	Experience float64 `yaml:"experience" json:"experience"`
+}
+
+// ScoringProfile is a named set of weights. NoMust, when set, replaces Weights
+// for job descriptions without must-have skills.
+type ScoringProfile struct {
+	Name        string   `yaml:"name" json:"name"`
+	Description string   `yaml:"description" json:"description"`
+	Weights     Weights  `yaml:"weights" json:"weights"`
+	NoMust      *Weights `yaml:"no_must" json:"noMust,omitempty"`
+}
+
+type profilesFile struct {
+	Profiles []ScoringProfile `yaml:"profiles"`
+}
+
+// LoadProfiles returns the built-in scoring profiles merged with the ones in
+// path (or RESUMEGPT_PROFILES). A profile in the file replaces the built-in
+// profile with the same name.
+func LoadProfiles(path string) ([]ScoringProfile, error) {
+	profiles, err := parseProfiles(defaultProfilesData)
+	if err != nil {
+		return nil, fmt.Errorf("%w: built-in: %v", ErrLoadProfiles, err)
+	}
+
+	path = strings.TrimSpace(path)
+	if path == "" {
+		path = strings.TrimSpace(Getenv("RESUMEGPT_PROFILES"))
+	}
+	if path == "" {
+		return profiles, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrLoadProfiles, err)
+	}
+	custom, err := parseProfiles(data)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s: %v", ErrLoadProfiles, path, err)
+	}
+	for _, p := range custom {
+		replaced := false
+		for i := range profiles {
+			if profiles[i].Name == p.Name {
+				profiles[i] = p
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			profiles = append(profiles, p)
+		}
+	}
+	return profiles, nil
+}
+
+func parseProfiles(data []byte) ([]ScoringProfile, error) {
+	var file profilesFile
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, err
+	}
+	for i := range file.Profiles {
+		p := &file.Profiles[i]
+		p.Name = strings.ToLower(strings.TrimSpace(p.Name))
+		if p.Name == "" {
+			return nil, errors.New("profile without a name")
+		}
+		if err := p.Weights.validate(); err != nil {
+			return nil, fmt.Errorf("profile %q: %v", p.Name, err)
+		}
+		if p.NoMust != nil {
+			if err := p.NoMust.validate(); err != nil {
+				return nil, fmt.Errorf("profile %q no_must: %v", p.Name, err)
+			}
+		}
+	}
+	return file.Profiles, nil
+}
+
+// selectProfile picks the named profile (default when empty) and applies the
+// weight overrides, if any.
+func selectProfile(profilesPath, name, overrides string) (ScoringProfile, error) {
+	profiles, err := LoadProfiles(profilesPath)
+	if err != nil {
+		return ScoringProfile{}, err
+	}
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "" {
+		name = DefaultProfile
+	}
+
+	var profile ScoringProfile
+	found := false
+	for _, p := range profiles {
+		if p.Name == name {
+			profile = p
+			found = true
+			break
+		}
+	}
+	if !found {
+		names := make([]string, 0, len(profiles))
+		for _, p := range profiles {
+			names = append(names, p.Name)
+		}
+		sort.Strings(names)
+		return ScoringProfile{}, fmt.Errorf("%w: %q (available: %s)", ErrUnknownProfile, name, strings.Join(names, ", "))
+	}
+
+	if strings.TrimSpace(overrides) != "" {
+		w, err := ParseWeights(overrides, profile.Weights)
+		if err != nil {
+			return ScoringProfile{}, err
+		}
+		profile.Weights = w
+		profile.NoMust = nil
+	}
+	return profile, nil
+}
+
+// ParseWeights applies overrides such as "similarity=0.5,must=0.3" on top of
+// base. Components that are not mentioned keep their base weight.
+func ParseWeights(spec string, base Weights) (Weights, error) {
+	w := base
+	parts := strings.FieldsFunc(spec, func(r rune) bool { return r == ',' || r == ';' })
+	for _, part := range parts {
+		key, val, ok := strings.Cut(part, "=")
+		if !ok {
+			return Weights{}, fmt.Errorf("%w: %q is not key=value", ErrInvalidWeights, strings.TrimSpace(part))
+		}
+		v, err := strconv.ParseFloat(strings.TrimSpace(val), 64)
+		if err != nil {
+			return Weights{}, fmt.Errorf("%w: %q: %v", ErrInvalidWeights, strings.TrimSpace(part), err)
+		}
+		switch strings.ToLower(strings.TrimSpace(key)) {
+		case "similarity", "sim":
+			w.Similarity = v
+		case "must":
+			w.Must = v
+		case "nice":
+			w.Nice = v
+		case "skills", "skill", "general":
+			w.Skills = v
+		case "experience", "exp":
+			w.Experience = v
+		default:
+			return Weights{}, fmt.Errorf("%w: unknown component %q", ErrInvalidWeights, strings.TrimSpace(key))
+		}
+	}
+	if err := w.validate(); err != nil {
+		return Weights{}, fmt.Errorf("%w: %v", ErrInvalidWeights, err)
+	}
+	return w, nil
+}
+
+func (w Weights) validate() error {
+	for _, v := range []float64{w.Similarity, w.Must, w.Nice, w.Skills, w.Experience} {
+		if v < 0 {
+			return errors.New("weights must not be negative")
+		}
+	}
+	if w.sum() == 0 {
+		return errors.New("at least one weight must be positive")
+	}
+	return nil
+}
+
+func (w Weights) sum() float64 {
+	return w.Similarity + w.Must + w.Nice + w.Skills + w.Experience
+}
+
+// resolve returns the weights for one run: components that do not apply are
+// dropped and the rest are normalized to sum to 1.
+func (p ScoringProfile) resolve(mustCount int, hasExperience bool) Weights {
+	w := p.Weights
+	if mustCount == 0 {
+		if p.NoMust != nil {
+			w = *p.NoMust
+		}
+		w.Must = 0
+	}
+	if !hasExperience {
+		w.Experience = 0
+	}
+	total := w.sum()
+	if total == 0 {
+		return Weights{Similarity: 1}
+	}
+	return Weights{
+		Similarity: w.Similarity / total,
+		Must:       w.Must / total,
+		Nice:       w.Nice / total,
+		Skills:     w.Skills / total,
+		Experience: w.Experience / total,
+	}
+}
@@ -0,0 +1,112 @@
+package matcher
+
+import (
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// Stages reported through Progress, in the order a run goes through them.
+// Heuristic mode skips jd, embed and explain.
+const (
+	StageExtract = "extract"
+	StageJD      = "jd"
+	StageEmbed   = "embed"
+	StageScore   = "score"
+	StageExplain = "explain"
+	StageWrite   = "write"
+)
+
+// Progress is one update of a running match: Done of Total items of Stage are
+// finished, and File is the item that just finished, if there is one.
+type Progress struct {
+	Stage string `json:"stage"`
+	Done  int    `json:"done"`
+	Total int    `json:"total"`
+	File  string `json:"file,omitempty"`
+}
+
+// ProgressFunc receives progress updates. Calls never overlap, so it does not
+// need to be safe for concurrent use, but it should return quickly since the
+// workers wait for it.
+type ProgressFunc func(Progress)
+
+// progressReporter counts finished items of the current stage and forwards
+// every change to fn. It also logs how long each stage took, once its last
+// item is done or the next stage starts. A nil reporter reports nothing.
+type progressReporter struct {
+	mu      sync.Mutex
+	fn      ProgressFunc
+	log     *slog.Logger
+	stage   string
+	done    int
+	total   int
+	started time.Time
+	logged  bool
+}
+
+func newProgressReporter(fn ProgressFunc, log *slog.Logger) *progressReporter {
+	if fn == nil && log == nil {
+		return nil
+	}
+	return &progressReporter{fn: fn, log: log}
+}
+
+// start begins a stage of total items, ending the previous one.
+func (p *progressReporter) start(stage string, total int) {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.endStage()
+	p.stage, p.done, p.total = stage, 0, total
+	p.started, p.logged = time.Now(), false
+	if p.fn != nil {
+		p.fn(Progress{Stage: stage, Total: total})
+	}
+}
+
+// finish ends the last stage.
+func (p *progressReporter) finish() {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.endStage()
+	p.stage = ""
+}
+
+func (p *progressReporter) endStage() {
+	if p.stage == "" || p.logged || p.log == nil {
+		return
+	}
+	p.logged = true
+	p.log.Info("stage finished",
+		"stage", p.stage,
+		"done", p.done,
+		"total", p.total,
+		"duration_ms", durationMS(time.Since(p.started)),
+	)
+}
+
+// step marks one item of the current stage as finished.
+func (p *progressReporter) step(file string) {
+	p.advance(1, file)
+}
+
+func (p *progressReporter) advance(n int, file string) {
+	if p == nil || n <= 0 {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.done += n
+	if p.fn != nil {
+		p.fn(Progress{Stage: p.stage, Done: p.done, Total: p.total, File: file})
+	}
+	if p.done >= p.total {
+		p.endStage()
+	}
+}
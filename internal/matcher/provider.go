@@ -0,0 +1,313 @@
+package matcher
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Providers selectable with RESUMEGPT_PROVIDER.
+const (
+	ProviderOpenAI     = "openai"
+	ProviderAzure      = "azure"
+	ProviderOllama     = "ollama"
+	ProviderCompatible = "openai-compatible"
+	ProviderLocal      = "local"
+)
+
+var ErrProvider = errors.New("invalid llm provider configuration")
+
+// Embedder turns texts into embedding vectors, one per text and in order.
+type Embedder interface {
+	Embed(ctx context.Context, texts []string) ([][]float64, error)
+}
+
+// StructuredChat sends a system and a user prompt to a chat model and decodes
+// its reply, which must be JSON matching schema, into out.
+type StructuredChat interface {
+	ChatJSON(ctx context.Context, schemaName string, schema map[string]any, system, user string, out any) error
+}
+
+// aiClient is what OpenAI mode runs on: a provider's embedder and chat model,
+// plus the settings and embedding cache that are the same for every provider.
+// chat is nil for the local provider, which only embeds.
+type aiClient struct {
+	provider string
+	embedder Embedder
+	chat     StructuredChat
+
+	explainTopN     int
+	embedBatchSize  int
+	embedChunkWords int
+	explainMaxChars int
+	// explainWorkers bounds concurrent explain calls.
+	explainWorkers int
+	embedCache     *embeddingCache
+	// usage counts tokens across the provider's calls; budgetUSD, when set,
+	// stops the explain phase once the estimated cost reaches it.
+	usage     *usageMeter
+	budgetUSD float64
+
+	statsMu    sync.Mutex
+	embedStats EmbeddingCacheStats
+}
+
+// mode is the Output mode of runs scored by c: "local" for the local
+// embedder, which calls no model, and "openai" for every other provider.
+func (c *aiClient) mode() string {
+	if c.provider == ProviderLocal {
+		return "local"
+	}
+	return "openai"
+}
+
+// providerModels are the default chat and embedding models of a provider;
+// RESUMEGPT_LLM_MODEL and RESUMEGPT_EMBED_MODEL override them.
+var providerModels = map[string][2]string{
+	ProviderOpenAI:     {"gpt-4o", "text-embedding-3-large"},
+	ProviderAzure:      {"gpt-4o", "text-embedding-3-large"},
+	ProviderCompatible: {"gpt-4o", "text-embedding-3-large"},
+	ProviderOllama:     {"llama3.1", "nomic-embed-text"},
+	ProviderLocal:      {"", "hashed-ngram"},
+}
+
+// newAIClientFromEnv builds the client for RESUMEGPT_PROVIDER (default
+// openai). Bad settings return ErrProvider. A missing API key returns
+// ErrMissingOpenAIKey only for the default provider, which callers may fall
+// back from; a provider that was chosen explicitly needs its key, so a
+// missing one is an ErrProvider too.
+func newAIClientFromEnv() (*aiClient, error) {
+	provider := strings.ToLower(strings.TrimSpace(Getenv("RESUMEGPT_PROVIDER")))
+	explicit := provider != ""
+	if !explicit {
+		provider = ProviderOpenAI
+	}
+	models, ok := providerModels[provider]
+	if !ok {
+		return nil, fmt.Errorf("%w: unknown provider %q (want openai, azure, ollama, openai-compatible or local)", ErrProvider, provider)
+	}
+	llmModel := envString("RESUMEGPT_LLM_MODEL", models[0])
+	embedModel := envString("RESUMEGPT_EMBED_MODEL", models[1])
+	temperature := clamp(envFloat("RESUMEGPT_LLM_TEMPERATURE", 0.2), 0, 1)
+	prices, err := loadPrices(strings.TrimSpace(Getenv("RESUMEGPT_PRICES")))
+	if err != nil {
+		return nil, err
+	}
+	usage := newUsageMeter(prices)
+
+	var (
+		embedder Embedder
+		chat     StructuredChat
+		cacheKey = embedModel
+	)
+	switch provider {
+	case ProviderOpenAI, ProviderCompatible:
+		apiKey := strings.TrimSpace(Getenv("OPENAI_API_KEY"))
+		baseURL := strings.TrimSpace(Getenv("OPENAI_BASE_URL"))
+		if provider == ProviderOpenAI {
+			if apiKey == "" && explicit {
+				return nil, fmt.Errorf("%w: OPENAI_API_KEY is required for the openai provider", ErrProvider)
+			}
+			if apiKey == "" {
+				return nil, ErrMissingOpenAIKey
+			}
+			if baseURL == "" {
+				baseURL = "https://api.openai.com/v1"
+			}
+		} else {
+			if baseURL == "" {
+				return nil, fmt.Errorf("%w: OPENAI_BASE_URL is required for the openai-compatible provider", ErrProvider)
+			}
+			cacheKey = provider + "-" + hostOf(baseURL) + "-" + embedModel
+		}
+		headers := map[string]string{}
+		if apiKey != "" {
+			headers["Authorization"] = "Bearer " + apiKey
+		}
+		p := &openAIProvider{
+			name:         provider,
+			api:          newAPITransport(baseURL, headers, 90*time.Second),
+			chatPath:     "/chat/completions",
+			embedPath:    "/embeddings",
+			llmModel:     llmModel,
+			embedModel:   embedModel,
+			temperature:  temperature,
+			strictSchema: envBool("RESUMEGPT_JSON_SCHEMA", true),
+			usage:        usage,
+		}
+		embedder, chat = p, p
+
+	case ProviderAzure:
+		apiKey := strings.TrimSpace(Getenv("AZURE_OPENAI_API_KEY"))
+		if apiKey == "" {
+			return nil, fmt.Errorf("%w: AZURE_OPENAI_API_KEY is required for the azure provider", ErrProvider)
+		}
+		endpoint := strings.TrimSpace(Getenv("AZURE_OPENAI_ENDPOINT"))
+		if endpoint == "" {
+			return nil, fmt.Errorf("%w: AZURE_OPENAI_ENDPOINT is required for the azure provider", ErrProvider)
+		}
+		version := url.QueryEscape(envString("AZURE_OPENAI_API_VERSION", "2024-10-21"))
+		chatDeployment := envString("AZURE_OPENAI_CHAT_DEPLOYMENT", llmModel)
+		embedDeployment := envString("AZURE_OPENAI_EMBED_DEPLOYMENT", embedModel)
+		p := &openAIProvider{
+			name:         provider,
+			api:          newAPITransport(endpoint, map[string]string{"api-key": apiKey}, 90*time.Second),
+			chatPath:     "/openai/deployments/" + url.PathEscape(chatDeployment) + "/chat/completions?api-version=" + version,
+			embedPath:    "/openai/deployments/" + url.PathEscape(embedDeployment) + "/embeddings?api-version=" + version,
+			llmModel:     llmModel,
+			embedModel:   embedModel,
+			temperature:  temperature,
+			strictSchema: envBool("RESUMEGPT_JSON_SCHEMA", true),
+			usage:        usage,
+		}
+		embedder, chat = p, p
+		cacheKey = provider + "-" + hostOf(endpoint) + "-" + embedDeployment
+
+	case ProviderOllama:
+		host := envString("OLLAMA_HOST", "http://localhost:11434")
+		if !strings.Contains(host, "://") {
+			host = "http://" + host
+		}
+		// Local models are much slower than the hosted APIs, especially on
+		// the first call while the model loads. They cost nothing per token.
+		prices[strings.ToLower(llmModel)] = ModelPrice{}
+		prices[strings.ToLower(embedModel)] = ModelPrice{}
+		p := &ollamaProvider{
+			api:         newAPITransport(host, nil, 5*time.Minute),
+			llmModel:    llmModel,
+			embedModel:  embedModel,
+			temperature: temperature,
+			usage:       usage,
+		}
+		embedder, chat = p, p
+		cacheKey = provider + "-" + embedModel
+
+	case ProviderLocal:
+		corpusDir := strings.TrimSpace(Getenv("RESUMEGPT_LOCAL_CORPUS"))
+		if corpusDir != "" && !dirExists(corpusDir) {
+			return nil, fmt.Errorf("%w: RESUMEGPT_LOCAL_CORPUS folder not found: %s", ErrProvider, corpusDir)
+		}
+		embedder = newLocalEmbedder(corpusDir)
+		// Local vectors depend on the corpus and are cheap to compute, so
+		// they are never cached. Nothing is billed either.
+		cacheKey = ""
+		usage = nil
+	}
+
+	var cache *embeddingCache
+	if cacheKey != "" {
+		cache = newEmbeddingCache(cacheKey)
+	}
+
+	return &aiClient{
+		provider:        provider,
+		embedder:        embedder,
+		chat:            chat,
+		explainTopN:     envInt("RESUMEGPT_EXPLAIN_TOPN", 20),
+		embedBatchSize:  max(1, envInt("RESUMEGPT_EMBED_BATCH", 96)),
+		embedChunkWords: max(500, envInt("RESUMEGPT_EMBED_CHUNK_WORDS", 2000)),
+		explainMaxChars: max(2000, envInt("RESUMEGPT_EXPLAIN_MAX_CHARS", 12000)),
+		explainWorkers:  envInt("RESUMEGPT_EXPLAIN_CONCURRENCY", 4),
+		embedCache:      cache,
+		usage:           usage,
+		budgetUSD:       envFloat("RESUMEGPT_MAX_COST_USD", 0),
+	}, nil
+}
+
+// embedTexts embeds texts in batches, skipping the ones already in the
+// embedding cache, and reports each finished batch to prog.
+func (c *aiClient) embedTexts(ctx context.Context, texts []string, prog *progressReporter) ([][]float64, error) {
+	cleaned := make([]string, 0, len(texts))
+	for _, t := range texts {
+		trimmed := strings.TrimSpace(t)
+		if trimmed == "" {
+			trimmed = "empty"
+		}
+		cleaned = append(cleaned, trimmed)
+	}
+
+	out := make([][]float64, len(cleaned))
+	misses := make([]int, 0, len(cleaned))
+	for i, text := range cleaned {
+		if vec, ok := c.embedCache.get(text); ok {
+			out[i] = vec
+			continue
+		}
+		misses = append(misses, i)
+	}
+	c.statsMu.Lock()
+	c.embedStats.Hits += len(cleaned) - len(misses)
+	c.embedStats.Misses += len(misses)
+	c.statsMu.Unlock()
+	prog.advance(len(cleaned)-len(misses), "")
+
+	for i := 0; i < len(misses); i += c.embedBatchSize {
+		end := i + c.embedBatchSize
+		if end > len(misses) {
+			end = len(misses)
+		}
+		batch := make([]string, 0, end-i)
+		for _, idx := range misses[i:end] {
+			batch = append(batch, cleaned[idx])
+		}
+		vecs, err := c.embedder.Embed(ctx, batch)
+		if err != nil {
+			return nil, err
+		}
+		for j, vec := range vecs {
+			if j >= len(batch) || len(vec) == 0 {
+				continue
+			}
+			idx := misses[i+j]
+			out[idx] = vec
+			c.embedCache.put(cleaned[idx], vec)
+		}
+		prog.advance(len(batch), "")
+	}
+	return out, nil
+}
+
+func (c *aiClient) embeddingStats() EmbeddingCacheStats {
+	c.statsMu.Lock()
+	defer c.statsMu.Unlock()
+	return c.embedStats
+}
+
+func newAPITransport(baseURL string, headers map[string]string, timeout time.Duration) *apiTransport {
+	return &apiTransport{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		headers:    headers,
+		httpClient: &http.Client{Timeout: timeout},
+		limiter:    newRateLimiter(envInt("RESUMEGPT_OPENAI_RPM", 500)),
+		retry:      retryPolicyFromEnv(),
+	}
+}
+
+func hostOf(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Host == "" {
+		return rawURL
+	}
+	return u.Host
+}
+
+func envString(key, def string) string {
+	if val := strings.TrimSpace(Getenv(key)); val != "" {
+		return val
+	}
+	return def
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if strings.TrimSpace(v) != "" {
+			return v
+		}
+	}
+	return ""
+}
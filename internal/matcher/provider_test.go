@@ -0,0 +1,42 @@
+package matcher
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestSelectClientMissingKey(t *testing.T) {
+	keys := []string{"RESUMEGPT_PROVIDER", "RESUMEGPT_REQUIRE_OPENAI", "RESUMEGPT_PRICES",
+		"OPENAI_API_KEY", "OPENAI_BASE_URL", "AZURE_OPENAI_API_KEY", "AZURE_OPENAI_ENDPOINT"}
+	tests := []struct {
+		name    string
+		env     map[string]string
+		wantErr error
+	}{
+		{"default provider falls back", nil, nil},
+		{"explicit openai", map[string]string{"RESUMEGPT_PROVIDER": "openai"}, ErrProvider},
+		{"azure without key", map[string]string{"RESUMEGPT_PROVIDER": "azure", "AZURE_OPENAI_ENDPOINT": "https://x.openai.azure.com"}, ErrProvider},
+		{"azure without endpoint", map[string]string{"RESUMEGPT_PROVIDER": "azure", "AZURE_OPENAI_API_KEY": "k"}, ErrProvider},
+		{"required default", map[string]string{"RESUMEGPT_REQUIRE_OPENAI": "1"}, ErrMissingOpenAIKey},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			configDirs(t, keys...)
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+			LoadDotEnv()
+			client, err := selectClient(context.Background(), false)
+			if tt.wantErr == nil {
+				if err != nil || client != nil {
+					t.Fatalf("selectClient = %v, %v, want heuristic mode", client, err)
+				}
+				return
+			}
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("selectClient error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
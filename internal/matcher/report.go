@@ -0,0 +1,96 @@
+package matcher
+
+import (
+	_ "embed"
+	"fmt"
+	"html/template"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ReportHTML selects the self-contained HTML shortlist with Input.Report.
+const ReportHTML = "html"
+
+//go:embed report/report.html.tmpl
+var reportTemplateText string
+
+var reportTemplate = template.Must(template.New("report").Funcs(template.FuncMap{
+	"join": strings.Join,
+	"add":  func(a, b int) int { return a + b },
+	"percent": func(v float64) float64 {
+		return v * 100
+	},
+	// bar is the width of a score bar in percent.
+	"bar": func(score float64) float64 {
+		return clamp(score, 0, 100)
+	},
+	"component": func(name string, c ScoreComponent) reportComponent {
+		return reportComponent{Name: name, ScoreComponent: c}
+	},
+	"skills": func(matched, missing []string) reportSkills {
+		return reportSkills{Matched: matched, Missing: missing}
+	},
+	"fileURL": fileURL,
+}).Parse(reportTemplateText))
+
+type reportData struct {
+	Title string
+	ResultsDocument
+}
+
+type reportComponent struct {
+	Name string
+	ScoreComponent
+}
+
+type reportSkills struct {
+	Matched []string
+	Missing []string
+}
+
+// normalizeReport checks a report name; "" means no report.
+func normalizeReport(report string) (string, error) {
+	report = strings.ToLower(strings.TrimSpace(report))
+	switch report {
+	case "", ReportHTML:
+		return report, nil
+	}
+	return "", fmt.Errorf("%w: report %q (want html)", ErrFormat, report)
+}
+
+// writeReportHTML renders the shortlist report: a single HTML file with
+// inline styles and no scripts, so it can be mailed or opened from a share.
+func writeReportHTML(path string, doc ResultsDocument) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	title := strings.TrimSuffix(filepath.Base(doc.JDPath), filepath.Ext(doc.JDPath))
+	if doc.JDInfo != nil && strings.TrimSpace(doc.JDInfo.RoleTitle) != "" {
+		title = doc.JDInfo.RoleTitle
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := reportTemplate.Execute(f, reportData{Title: title, ResultsDocument: doc}); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// fileURL links a resume as a file:// URL, which html/template would
+// otherwise replace as unsafe.
+func fileURL(path string) template.URL {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		abs = path
+	}
+	p := filepath.ToSlash(abs)
+	if !strings.HasPrefix(p, "/") {
+		p = "/" + p
+	}
+	return template.URL((&url.URL{Scheme: "file", Path: p}).String())
+}
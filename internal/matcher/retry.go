@@ -0,0 +1,160 @@
+package matcher
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	ErrProviderRateLimited = errors.New("provider rate limit exceeded")
+	ErrProviderAuth        = errors.New("provider authentication failed")
+	ErrProviderQuota       = errors.New("provider quota exceeded")
+	ErrProviderBadRequest  = errors.New("provider rejected the request")
+	ErrProviderUnavailable = errors.New("provider unavailable")
+)
+
+// APIError is a failed provider request. It wraps one of the ErrProvider*
+// errors, so callers can use errors.Is to tell the kinds apart.
+type APIError struct {
+	Kind       error
+	StatusCode int
+	Type       string
+	Code       string
+	Message    string
+	// RetryAfter is the wait the server asked for, if any.
+	RetryAfter time.Duration
+	// Attempts is how many requests were made before giving up.
+	Attempts int
+}
+
+func (e *APIError) Error() string {
+	msg := e.Message
+	if msg == "" {
+		msg = http.StatusText(e.StatusCode)
+	}
+	if e.StatusCode == 0 {
+		return fmt.Sprintf("%v: %s (after %d attempts)", e.Kind, msg, e.Attempts)
+	}
+	return fmt.Sprintf("%v: http %d: %s (after %d attempts)", e.Kind, e.StatusCode, msg, e.Attempts)
+}
+
+func (e *APIError) Unwrap() error {
+	return e.Kind
+}
+
+func (e *APIError) retryable() bool {
+	return e.Kind == ErrProviderRateLimited || e.Kind == ErrProviderUnavailable
+}
+
+// retryPolicy caps retries and sets the exponential backoff between them.
+type retryPolicy struct {
+	maxRetries int
+	baseDelay  time.Duration
+	maxDelay   time.Duration
+	// sleep waits between attempts; tests can replace it.
+	sleep func(ctx context.Context, d time.Duration) error
+}
+
+func retryPolicyFromEnv() retryPolicy {
+	return retryPolicy{
+		maxRetries: envNonNegativeInt("RESUMEGPT_OPENAI_MAX_RETRIES", 4),
+		baseDelay:  time.Duration(envInt("RESUMEGPT_OPENAI_BACKOFF_MS", 1000)) * time.Millisecond,
+		maxDelay:   time.Duration(envInt("RESUMEGPT_OPENAI_MAX_BACKOFF_MS", 60000)) * time.Millisecond,
+		sleep:      sleepContext,
+	}
+}
+
+// backoff returns the wait before retry number attempt (0-based): exponential
+// with jitter in [50%, 100%], but never shorter than what the server asked for.
+func (p retryPolicy) backoff(attempt int, serverDelay time.Duration) time.Duration {
+	d := p.baseDelay << attempt
+	if d <= 0 || d > p.maxDelay {
+		d = p.maxDelay
+	}
+	d = d/2 + time.Duration(rand.Int63n(int64(d/2)+1))
+	if serverDelay > d {
+		d = min(serverDelay, p.maxDelay)
+	}
+	return d
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// classifyResponse turns a non-2xx response into an APIError.
+func classifyResponse(resp *http.Response, apiErr openAIErrorResponse) *APIError {
+	e := &APIError{
+		StatusCode: resp.StatusCode,
+		Type:       apiErr.Error.Type,
+		Code:       apiErr.Error.Code,
+		Message:    apiErr.Error.Message,
+		RetryAfter: retryAfter(resp.Header),
+	}
+	switch {
+	case resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden:
+		e.Kind = ErrProviderAuth
+	case resp.StatusCode == http.StatusTooManyRequests && (e.Code == "insufficient_quota" || e.Type == "insufficient_quota"):
+		e.Kind = ErrProviderQuota
+	case resp.StatusCode == http.StatusTooManyRequests:
+		e.Kind = ErrProviderRateLimited
+	case resp.StatusCode == http.StatusRequestTimeout || resp.StatusCode == http.StatusConflict || resp.StatusCode >= 500:
+		e.Kind = ErrProviderUnavailable
+	default:
+		e.Kind = ErrProviderBadRequest
+	}
+	return e
+}
+
+// retryAfter reads the wait the server asked for from Retry-After,
+// retry-after-ms or, when a limit is used up, the x-ratelimit-reset-* headers.
+func retryAfter(h http.Header) time.Duration {
+	if ms, err := strconv.ParseFloat(strings.TrimSpace(h.Get("retry-after-ms")), 64); err == nil && ms > 0 {
+		return time.Duration(ms * float64(time.Millisecond))
+	}
+	if v := strings.TrimSpace(h.Get("Retry-After")); v != "" {
+		if secs, err := strconv.ParseFloat(v, 64); err == nil && secs > 0 {
+			return time.Duration(secs * float64(time.Second))
+		}
+		if t, err := http.ParseTime(v); err == nil {
+			if d := time.Until(t); d > 0 {
+				return d
+			}
+		}
+	}
+	wait := time.Duration(0)
+	for _, kind := range []string{"requests", "tokens"} {
+		if strings.TrimSpace(h.Get("x-ratelimit-remaining-"+kind)) != "0" {
+			continue
+		}
+		if d, err := time.ParseDuration(strings.TrimSpace(h.Get("x-ratelimit-reset-" + kind))); err == nil && d > wait {
+			wait = d
+		}
+	}
+	return wait
+}
+
+func envNonNegativeInt(key string, def int) int {
+	raw := strings.TrimSpace(Getenv(key))
+	if raw == "" {
+		return def
+	}
+	val, err := strconv.Atoi(raw)
+	if err != nil || val < 0 {
+		return def
+	}
+	return val
+}
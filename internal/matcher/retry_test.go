@@ -0,0 +1,238 @@
+package matcher
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// reply is one canned response of a test server.
+type reply struct {
+	status  int
+	headers map[string]string
+	body    string
+}
+
+// testTransport returns a transport whose server answers with replies in
+// order (repeating the last one), the request counter and the recorded waits.
+func testTransport(t *testing.T, maxRetries int, replies ...reply) (*apiTransport, *atomic.Int32, *[]time.Duration) {
+	t.Helper()
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := int(calls.Add(1)) - 1
+		if n >= len(replies) {
+			n = len(replies) - 1
+		}
+		rep := replies[n]
+		for k, v := range rep.headers {
+			w.Header().Set(k, v)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(rep.status)
+		_, _ = w.Write([]byte(rep.body))
+	}))
+	t.Cleanup(srv.Close)
+
+	var waits []time.Duration
+	tr := &apiTransport{
+		baseURL:    srv.URL,
+		httpClient: srv.Client(),
+		retry: retryPolicy{
+			maxRetries: maxRetries,
+			baseDelay:  10 * time.Millisecond,
+			maxDelay:   time.Minute,
+			sleep: func(ctx context.Context, d time.Duration) error {
+				waits = append(waits, d)
+				return nil
+			},
+		},
+	}
+	return tr, &calls, &waits
+}
+
+const okBody = `{"ok": true}`
+
+func errorBody(typ, code, msg string) string {
+	return `{"error": {"type": "` + typ + `", "code": "` + code + `", "message": "` + msg + `"}}`
+}
+
+func TestDoJSONRetriesThenSucceeds(t *testing.T) {
+	for _, status := range []int{http.StatusTooManyRequests, http.StatusInternalServerError, http.StatusBadGateway, http.StatusServiceUnavailable} {
+		t.Run(http.StatusText(status), func(t *testing.T) {
+			tr, calls, waits := testTransport(t, 3,
+				reply{status: status, body: errorBody("server_error", "", "try again")},
+				reply{status: http.StatusOK, body: okBody},
+			)
+			var out struct{ OK bool }
+			if err := tr.doJSON(context.Background(), "/x", map[string]string{}, &out); err != nil {
+				t.Fatalf("doJSON: %v", err)
+			}
+			if !out.OK {
+				t.Error("response not decoded")
+			}
+			if got := calls.Load(); got != 2 {
+				t.Errorf("requests = %d, want 2", got)
+			}
+			if len(*waits) != 1 {
+				t.Errorf("waits = %v, want one", *waits)
+			}
+		})
+	}
+}
+
+func TestDoJSONGivesUpAfterMaxRetries(t *testing.T) {
+	tr, calls, waits := testTransport(t, 2, reply{status: http.StatusServiceUnavailable, body: "overloaded"})
+	err := tr.doJSON(context.Background(), "/x", map[string]string{}, &struct{}{})
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("err = %v, want *APIError", err)
+	}
+	if !errors.Is(err, ErrProviderUnavailable) {
+		t.Errorf("err = %v, want ErrProviderUnavailable", err)
+	}
+	if apiErr.Attempts != 3 || calls.Load() != 3 || len(*waits) != 2 {
+		t.Errorf("attempts = %d, requests = %d, waits = %d; want 3, 3, 2", apiErr.Attempts, calls.Load(), len(*waits))
+	}
+	if apiErr.StatusCode != http.StatusServiceUnavailable || apiErr.Message != "overloaded" {
+		t.Errorf("status %d message %q", apiErr.StatusCode, apiErr.Message)
+	}
+}
+
+func TestDoJSONHonorsServerDelay(t *testing.T) {
+	tests := []struct {
+		name    string
+		headers map[string]string
+		want    time.Duration
+	}{
+		{"retry-after seconds", map[string]string{"Retry-After": "7"}, 7 * time.Second},
+		{"retry-after-ms", map[string]string{"retry-after-ms": "2500", "Retry-After": "9"}, 2500 * time.Millisecond},
+		{"requests limit reset", map[string]string{"x-ratelimit-remaining-requests": "0", "x-ratelimit-reset-requests": "3s"}, 3 * time.Second},
+		{"tokens limit reset", map[string]string{"x-ratelimit-remaining-tokens": "0", "x-ratelimit-reset-tokens": "1m30s", "x-ratelimit-remaining-requests": "0", "x-ratelimit-reset-requests": "2s"}, 90 * time.Second},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tr, _, waits := testTransport(t, 1,
+				reply{status: http.StatusTooManyRequests, headers: tt.headers, body: errorBody("requests", "rate_limit_exceeded", "slow down")},
+				reply{status: http.StatusOK, body: okBody},
+			)
+			tr.retry.maxDelay = 2 * time.Minute
+			if err := tr.doJSON(context.Background(), "/x", map[string]string{}, &struct{}{}); err != nil {
+				t.Fatalf("doJSON: %v", err)
+			}
+			if len(*waits) != 1 || (*waits)[0] != tt.want {
+				t.Errorf("waits = %v, want [%v]", *waits, tt.want)
+			}
+		})
+	}
+}
+
+func TestRetryAfterIgnoresResetWhileQuotaRemains(t *testing.T) {
+	h := http.Header{}
+	h.Set("x-ratelimit-remaining-requests", "12")
+	h.Set("x-ratelimit-reset-requests", "20s")
+	if got := retryAfter(h); got != 0 {
+		t.Errorf("retryAfter = %v, want 0", got)
+	}
+}
+
+func TestBackoffCapsServerDelay(t *testing.T) {
+	p := retryPolicy{baseDelay: time.Second, maxDelay: 5 * time.Second}
+	if got := p.backoff(0, time.Hour); got != 5*time.Second {
+		t.Errorf("backoff = %v, want the 5s cap", got)
+	}
+	for attempt := range 4 {
+		d := p.backoff(attempt, 0)
+		full := min(time.Second<<attempt, p.maxDelay)
+		if d < full/2 || d > full {
+			t.Errorf("backoff(%d) = %v, want within [%v, %v]", attempt, d, full/2, full)
+		}
+	}
+}
+
+func TestDoJSONDoesNotRetryClientErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		body   string
+		want   error
+		code   string
+	}{
+		{"unauthorized", http.StatusUnauthorized, errorBody("invalid_request_error", "invalid_api_key", "Incorrect API key"), ErrProviderAuth, "invalid_api_key"},
+		{"forbidden", http.StatusForbidden, errorBody("", "", "no access"), ErrProviderAuth, ""},
+		{"bad request", http.StatusBadRequest, errorBody("invalid_request_error", "context_length_exceeded", "too long"), ErrProviderBadRequest, "context_length_exceeded"},
+		{"not found", http.StatusNotFound, errorBody("invalid_request_error", "model_not_found", "no such model"), ErrProviderBadRequest, "model_not_found"},
+		{"quota", http.StatusTooManyRequests, errorBody("insufficient_quota", "insufficient_quota", "out of credits"), ErrProviderQuota, "insufficient_quota"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tr, calls, waits := testTransport(t, 4, reply{status: tt.status, body: tt.body})
+			err := tr.doJSON(context.Background(), "/x", map[string]string{}, &struct{}{})
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("err = %v, want %v", err, tt.want)
+			}
+			var apiErr *APIError
+			if !errors.As(err, &apiErr) {
+				t.Fatalf("err = %T, want *APIError", err)
+			}
+			if apiErr.StatusCode != tt.status || apiErr.Code != tt.code || apiErr.Attempts != 1 {
+				t.Errorf("got status %d code %q attempts %d", apiErr.StatusCode, apiErr.Code, apiErr.Attempts)
+			}
+			if calls.Load() != 1 || len(*waits) != 0 {
+				t.Errorf("requests = %d, waits = %v; want no retry", calls.Load(), *waits)
+			}
+		})
+	}
+}
+
+func TestClassifyResponse(t *testing.T) {
+	tests := []struct {
+		status int
+		typ    string
+		want   error
+	}{
+		{http.StatusUnauthorized, "", ErrProviderAuth},
+		{http.StatusForbidden, "", ErrProviderAuth},
+		{http.StatusTooManyRequests, "insufficient_quota", ErrProviderQuota},
+		{http.StatusTooManyRequests, "requests", ErrProviderRateLimited},
+		{http.StatusRequestTimeout, "", ErrProviderUnavailable},
+		{http.StatusConflict, "", ErrProviderUnavailable},
+		{http.StatusInternalServerError, "", ErrProviderUnavailable},
+		{http.StatusGatewayTimeout, "", ErrProviderUnavailable},
+		{http.StatusBadRequest, "", ErrProviderBadRequest},
+		{http.StatusUnprocessableEntity, "", ErrProviderBadRequest},
+	}
+	for _, tt := range tests {
+		var body openAIErrorResponse
+		body.Error.Type = tt.typ
+		e := classifyResponse(&http.Response{StatusCode: tt.status, Header: http.Header{}}, body)
+		if e.Kind != tt.want {
+			t.Errorf("%d %q: kind = %v, want %v", tt.status, tt.typ, e.Kind, tt.want)
+		}
+		retry := tt.want == ErrProviderRateLimited || tt.want == ErrProviderUnavailable
+		if e.retryable() != retry {
+			t.Errorf("%d %q: retryable = %v, want %v", tt.status, tt.typ, e.retryable(), retry)
+		}
+	}
+}
+
+func TestChatJSONErrorsNameProvider(t *testing.T) {
+	tests := []struct {
+		body, want string
+	}{
+		{`{"choices": []}`, "azure: empty response"},
+		{`{"choices": [{"message": {"refusal": "no"}}]}`, "azure refusal: no"},
+	}
+	for _, tt := range tests {
+		tr, _, _ := testTransport(t, 0, reply{status: 200, body: tt.body})
+		p := &openAIProvider{name: ProviderAzure, api: tr, usage: newUsageMeter(nil)}
+		var out map[string]any
+		err := p.ChatJSON(context.Background(), "test", map[string]any{}, "system", "user", &out)
+		if err == nil || err.Error() != tt.want {
+			t.Errorf("ChatJSON(%s) error = %v, want %q", tt.body, err, tt.want)
+		}
+	}
+}
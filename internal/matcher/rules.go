@@ -0,0 +1,322 @@
+package matcher
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	KnockoutRank    = "rank"
+	KnockoutExclude = "exclude"
+)
+
+var (
+	ErrLoadRules    = errors.New("failed to load knockout rules")
+	ErrKnockoutMode = errors.New("invalid knockout mode")
+)
+
+// KnockoutRule is a hard requirement checked before scoring. Type is one of
+// "skills" (must have all Skills), "min_years" (at least Years of experience),
+// "degree" (a degree of at least Level, in one of Values when set) or
+// "certification" (at least one of Values).
+type KnockoutRule struct {
+	Name   string   `yaml:"name" json:"name"`
+	Type   string   `yaml:"type" json:"type"`
+	Skills []string `yaml:"skills" json:"skills,omitempty"`
+	Years  float64  `yaml:"years" json:"years,omitempty"`
+	Level  string   `yaml:"level" json:"level,omitempty"`
+	Values []string `yaml:"values" json:"values,omitempty"`
+}
+
+// RulesFile is the on-disk shape of a knockout rules file (YAML or JSON).
+type RulesFile struct {
+	Mode   string         `yaml:"mode" json:"mode"`
+	FromJD JDKnockouts    `yaml:"from_jd" json:"from_jd"`
+	Rules  []KnockoutRule `yaml:"rules" json:"rules"`
+}
+
+// JDKnockouts selects which requirements found in the JD become rules.
+type JDKnockouts struct {
+	MinYears       bool `yaml:"min_years" json:"min_years"`
+	Certifications bool `yaml:"certifications" json:"certifications"`
+	Education      bool `yaml:"education" json:"education"`
+	MustSkills     bool `yaml:"must_skills" json:"must_skills"`
+}
+
+type ruleSet struct {
+	mode   string
+	fromJD JDKnockouts
+	rules  []KnockoutRule
+}
+
+// degreeLevels are the degree levels from lowest to highest. Plain BS, BA,
+// MS and MA are also initials, titles ("Senior BA") and state codes
+// ("Springfield MA 01101"), so they only match in capitals and followed by
+// degree context: "in", "of", "degree" or a field of study, as in "BA in
+// History", "BS, Computer Science" or "BS/MS Physics". MS and MA never match
+// right after a comma, where they are a state ("Boston, MA").
+var degreeLevels = []struct {
+	level string
+	re    *regexp.Regexp
+}{
+	{"associate", regexp.MustCompile(`(?i)\bassociate(?:'?s)?\s+(?:degree|of)\b`)},
+	{"bachelor", regexp.MustCompile(`(?i)(?:\bbachelor(?:'?s)?\b|\bb\.?\s?sc\b|\bb\.s\.|\bb\.a\.|\bb\.?\s?eng\b|\bb\.?\s?tech\b|\bbba\b|\b(?-i:BS|BA)` + degreeContext + `)`)},
+	{"master", regexp.MustCompile(`(?i)(?:\bmaster(?:'?s)?\s+(?:of|degree|in)\b|\bm\.?\s?sc\b|\bm\.s\.|\bm\.a\.|\bm\.?\s?eng\b|\bm\.?\s?tech\b|\bmba\b|(?:^|[^,\s]\s+|[(/:]\s*)(?-i:MS|MA)` + degreeContext + `)`)},
+	{"phd", regexp.MustCompile(`(?i)(?:\bph\.?\s?d\b|\bdoctorate\b|\bdoctoral\b)`)},
+}
+
+// degreeContext is what has to follow a plain BS, BA, MS or MA: more degrees
+// joined by slashes, then "in", "of", "degree" or a field of study.
+const degreeContext = `(?:\s*/\s*(?-i:BS|BA|MS|MA|PhD))*` +
+	`(?:\s+(?:in|of)\s|\s+degrees?\b|[,:]?\s+(?:computer|science|engineering|math|mathematics|statistics|economics|physics|chemistry|biology|business|finance|accounting|english|history|psychology|information|data|electrical|mechanical|civil|chemical|literature|management|marketing|education|communications?|political|philosophy|nursing|analytics|applied|software)\b)`
+
+// msProductRe matches Microsoft products such as "MS Excel", which are not a
+// Master of Science.
+var msProductRe = regexp.MustCompile(`(?i)\bMS[\s-]+(?:office|excel|word|powerpoint|access|outlook|project|visio|teams|sql|dynamics|windows|dos)\b`)
+
+// loadRules reads the rules file at path (or RESUMEGPT_RULES). fromJD and mode
+// override what the file says when set.
+func loadRules(path string, fromJD bool, mode string) (*ruleSet, error) {
+	rs := &ruleSet{mode: KnockoutRank}
+
+	path = strings.TrimSpace(path)
+	if path == "" {
+		path = strings.TrimSpace(Getenv("RESUMEGPT_RULES"))
+	}
+	if path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrLoadRules, err)
+		}
+		var file RulesFile
+		if err := yaml.Unmarshal(data, &file); err != nil {
+			return nil, fmt.Errorf("%w: %s: %v", ErrLoadRules, path, err)
+		}
+		for i, r := range file.Rules {
+			rule, err := normalizeRule(r)
+			if err != nil {
+				return nil, fmt.Errorf("%w: %s: rule %d: %v", ErrLoadRules, path, i+1, err)
+			}
+			rs.rules = append(rs.rules, rule)
+		}
+		rs.fromJD = file.FromJD
+		if strings.TrimSpace(file.Mode) != "" {
+			rs.mode = strings.ToLower(strings.TrimSpace(file.Mode))
+		}
+	}
+
+	if fromJD {
+		rs.fromJD.MinYears = true
+		rs.fromJD.Certifications = true
+		rs.fromJD.Education = true
+	}
+	if strings.TrimSpace(mode) != "" {
+		rs.mode = strings.ToLower(strings.TrimSpace(mode))
+	}
+	if rs.mode != KnockoutRank && rs.mode != KnockoutExclude {
+		return nil, fmt.Errorf("%w: %q (use %s or %s)", ErrKnockoutMode, rs.mode, KnockoutRank, KnockoutExclude)
+	}
+	return rs, nil
+}
+
+func normalizeRule(r KnockoutRule) (KnockoutRule, error) {
+	r.Type = strings.ToLower(strings.TrimSpace(r.Type))
+	r.Level = strings.ToLower(strings.TrimSpace(r.Level))
+	r.Skills = cleanList(r.Skills)
+	r.Values = cleanList(r.Values)
+	switch r.Type {
+	case "skills":
+		if len(r.Skills) == 0 {
+			return r, errors.New("skills rule needs skills")
+		}
+	case "min_years":
+		if r.Years <= 0 {
+			return r, errors.New("min_years rule needs years > 0")
+		}
+	case "degree":
+		if r.Level != "" && degreeRank(r.Level) < 0 {
+			return r, fmt.Errorf("unknown degree level %q", r.Level)
+		}
+		if r.Level == "" && len(r.Values) == 0 {
+			return r, errors.New("degree rule needs a level or values")
+		}
+	case "certification":
+		if len(r.Values) == 0 {
+			return r, errors.New("certification rule needs values")
+		}
+	default:
+		return r, fmt.Errorf("unknown rule type %q", r.Type)
+	}
+	if strings.TrimSpace(r.Name) == "" {
+		r.Name = ruleName(r)
+	}
+	return r, nil
+}
+
+func ruleName(r KnockoutRule) string {
+	switch r.Type {
+	case "skills":
+		return "skills: " + strings.Join(r.Skills, ", ")
+	case "min_years":
+		return fmt.Sprintf("at least %g years", r.Years)
+	case "degree":
+		name := "degree"
+		if r.Level != "" {
+			name = r.Level + " degree"
+		}
+		if len(r.Values) > 0 {
+			name += " in " + strings.Join(r.Values, " or ")
+		}
+		return name
+	default:
+		return "certification: " + strings.Join(r.Values, " or ")
+	}
+}
+
+// withJD returns the configured rules plus the ones derived from the JD.
+func (rs *ruleSet) withJD(jd JDExtract, mustSkills []string) []KnockoutRule {
+	rules := append([]KnockoutRule{}, rs.rules...)
+	if rs.fromJD.MustSkills && len(mustSkills) > 0 {
+		rules = append(rules, KnockoutRule{Type: "skills", Skills: mustSkills})
+	}
+	if rs.fromJD.MinYears && jd.YearsExperienceMin > 0 {
+		rules = append(rules, KnockoutRule{Type: "min_years", Years: jd.YearsExperienceMin})
+	}
+	if rs.fromJD.Certifications && len(jd.Certifications) > 0 {
+		rules = append(rules, KnockoutRule{Type: "certification", Values: cleanList(jd.Certifications)})
+	}
+	if rs.fromJD.Education {
+		if level := lowestDegreeLevel(jd.Education); level != "" {
+			rules = append(rules, KnockoutRule{Type: "degree", Level: level})
+		}
+	}
+	for i := range rules {
+		if rules[i].Name == "" {
+			rules[i].Name = ruleName(rules[i])
+		}
+	}
+	return rules
+}
+
+// failedRules returns the names of the rules a resume does not satisfy.
+func failedRules(tax *skillTaxonomy, rules []KnockoutRule, doc resumeDoc, resSkills map[string]bool, years float64) []string {
+	failed := []string{}
+	tokens := strings.Fields(doc.Norm)
+	for _, r := range rules {
+		ok := true
+		switch r.Type {
+		case "skills":
+			found := newSkillMatcher(tax, r.Skills).match(tokens)
+			for _, s := range r.Skills {
+				c := tax.canonical(s)
+				if !found[c] && !resSkills[c] {
+					ok = false
+					break
+				}
+			}
+		case "min_years":
+			ok = years >= r.Years
+		case "degree":
+			ok = hasDegree(tax, doc.Raw, r.Level, r.Values)
+		case "certification":
+			found := newSkillMatcher(tax, r.Values).match(tokens)
+			ok = len(found) > 0
+		}
+		if !ok {
+			failed = append(failed, r.Name)
+		}
+	}
+	return failed
+}
+
+// hasDegree looks for a degree of at least level on education lines, and for
+// one of the fields on the same line when fields are given.
+func hasDegree(tax *skillTaxonomy, raw, level string, fields []string) bool {
+	minRank := degreeRank(level)
+	var fieldMatcher *skillMatcher
+	if len(fields) > 0 {
+		fieldMatcher = newSkillMatcher(tax, fields)
+	}
+	for _, line := range strings.Split(raw, "\n") {
+		rank := highestDegree(line)
+		if rank < 0 && !isEducationLine(line) {
+			continue
+		}
+		if minRank >= 0 && rank < minRank {
+			continue
+		}
+		if fieldMatcher == nil || len(fieldMatcher.match(skillTokens(line))) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// highestDegree is the index in degreeLevels of the highest degree named in
+// text, or -1.
+func highestDegree(text string) int {
+	text = msProductRe.ReplaceAllString(text, " ")
+	rank := -1
+	for i, d := range degreeLevels {
+		if d.re.MatchString(text) {
+			rank = i
+		}
+	}
+	return rank
+}
+
+func degreeRank(level string) int {
+	for i, d := range degreeLevels {
+		if d.level == level {
+			return i
+		}
+	}
+	return -1
+}
+
+func lowestDegreeLevel(education []string) string {
+	lowest := -1
+	for _, e := range education {
+		e = msProductRe.ReplaceAllString(e, " ")
+		for i, d := range degreeLevels {
+			if d.re.MatchString(e) && (lowest < 0 || i < lowest) {
+				lowest = i
+			}
+		}
+	}
+	if lowest < 0 {
+		return ""
+	}
+	return degreeLevels[lowest].level
+}
+
+// rankResults orders candidates who pass every knockout rule ahead of those
+// who do not, then by score, drops knocked-out candidates in exclude mode and
+// assigns ranks.
+func rankResults(results []Result, mode string) []Result {
+	if mode == KnockoutExclude {
+		kept := results[:0]
+		for _, r := range results {
+			if !r.KnockedOut {
+				kept = append(kept, r)
+			}
+		}
+		results = kept
+	}
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].KnockedOut != results[j].KnockedOut {
+			return !results[i].KnockedOut
+		}
+		return results[i].Score > results[j].Score
+	})
+	for i := range results {
+		results[i].Rank = i + 1
+	}
+	return results
+}
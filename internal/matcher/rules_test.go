@@ -0,0 +1,74 @@
+package matcher
+
+import "testing"
+
+func TestHighestDegree(t *testing.T) {
+	tests := []struct {
+		line string
+		want string
+	}{
+		{"BS in Computer Science, State University 2012 - 2016", "bachelor"},
+		{"B.S. Computer Science", "bachelor"},
+		{"BSc Mathematics", "bachelor"},
+		{"BA, Economics", "bachelor"},
+		{"Bachelor of Arts", "bachelor"},
+		{"MS Statistics, Ohio State", "master"},
+		{"MA in English Literature", "master"},
+		{"Education: MS, Computer Science", "master"},
+		{"BS/MS Computer Science", "master"},
+		{"Combined BS/MS in Physics", "master"},
+		{"MS degree, 2019", "master"},
+		{"BA in History", "bachelor"},
+		{"M.Sc. Physics", "master"},
+		{"MBA, Finance", "master"},
+		{"PhD Physics", "phd"},
+		{"Associate of Science", "associate"},
+		{"Harvard University, Cambridge, MA", ""},
+		{"Jackson, MS 39201", ""},
+		{"MS Excel, MS-Word and MS SQL Server", ""},
+		{"Tools: ms sql, bs4, ma", ""},
+		{"Senior Data Analyst", ""},
+		{"Springfield MA 01101", ""},
+		{"Senior BA (business analyst), Acme Corp", ""},
+		{"Worked as a BA on the billing team", ""},
+		{"Combined BS (MS pending)", ""},
+		{"Acronyms: BS/MS, KPI, ROI", ""},
+		{"Boston, MA, Finance District", ""},
+	}
+	for _, tt := range tests {
+		got := ""
+		if rank := highestDegree(tt.line); rank >= 0 {
+			got = degreeLevels[rank].level
+		}
+		if got != tt.want {
+			t.Errorf("highestDegree(%q) = %q, want %q", tt.line, got, tt.want)
+		}
+	}
+}
+
+func TestHasDegree(t *testing.T) {
+	raw := "Acme Corp, Boston, MA\nSenior BA, Springfield MA 01101\nMS Office, SQL\nBS in Economics, State University 2010 - 2014"
+	if !hasDegree(defaultTaxonomy, raw, "bachelor", nil) {
+		t.Error("BS not accepted as a bachelor's degree")
+	}
+	if hasDegree(defaultTaxonomy, raw, "master", nil) {
+		t.Error("state code or MS Office taken for a master's degree")
+	}
+}
+
+func TestLowestDegreeLevel(t *testing.T) {
+	tests := []struct {
+		education []string
+		want      string
+	}{
+		{[]string{"BS or BA in Computer Science"}, "bachelor"},
+		{[]string{"MS in Statistics", "PhD preferred"}, "master"},
+		{[]string{"Proficiency in MS Excel"}, ""},
+		{nil, ""},
+	}
+	for _, tt := range tests {
+		if got := lowestDegreeLevel(tt.education); got != tt.want {
+			t.Errorf("lowestDegreeLevel(%q) = %q, want %q", tt.education, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,100 @@
+package matcher
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+var ErrLogLevel = errors.New("invalid log level")
+
+// LogLevelOff turns the run log off.
+const LogLevelOff = "off"
+
+type loggerKey struct{}
+
+// ParseLogLevel reads debug, info, warn or error; "" is info.
+func ParseLogLevel(level string) (slog.Level, error) {
+	switch strings.ToLower(strings.TrimSpace(level)) {
+	case "debug":
+		return slog.LevelDebug, nil
+	case "", "info":
+		return slog.LevelInfo, nil
+	case "warn", "warning":
+		return slog.LevelWarn, nil
+	case "error":
+		return slog.LevelError, nil
+	}
+	return 0, fmt.Errorf("%w: %q (want debug, info, warn, error or off)", ErrLogLevel, level)
+}
+
+// logLevel is Input.LogLevel, or RESUMEGPT_LOG_LEVEL when that is empty.
+func logLevel(input Input) string {
+	if level := strings.TrimSpace(input.LogLevel); level != "" {
+		return strings.ToLower(level)
+	}
+	return strings.ToLower(envString("RESUMEGPT_LOG_LEVEL", "info"))
+}
+
+func checkLogLevel(input Input) error {
+	level := logLevel(input)
+	if level == LogLevelOff {
+		return nil
+	}
+	_, err := ParseLogLevel(level)
+	return err
+}
+
+// runLogPath is the JSON log of one run: logs/<run id>.jsonl next to the
+// results.
+func runLogPath(outPath, runID string) string {
+	return filepath.Join(filepath.Dir(outPath), "logs", runID+".jsonl")
+}
+
+// openRunLog creates the run's JSON log file. Every record carries the run
+// ID. When the level is off or the file cannot be created the run goes on
+// with a logger that drops everything and an empty path.
+func openRunLog(input Input, outPath, runID string) (*slog.Logger, string, func()) {
+	level := logLevel(input)
+	if level == LogLevelOff {
+		return discardLogger, "", func() {}
+	}
+	lvl, err := ParseLogLevel(level)
+	if err != nil {
+		return discardLogger, "", func() {}
+	}
+	path := runLogPath(outPath, runID)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return discardLogger, "", func() {}
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return discardLogger, "", func() {}
+	}
+	h := slog.NewJSONHandler(f, &slog.HandlerOptions{Level: lvl})
+	return slog.New(h).With("run", runID), path, func() { _ = f.Close() }
+}
+
+var discardLogger = slog.New(slog.DiscardHandler)
+
+func withLogger(ctx context.Context, log *slog.Logger) context.Context {
+	return context.WithValue(ctx, loggerKey{}, log)
+}
+
+// logFrom returns the run logger carried by ctx; outside a run it drops
+// everything.
+func logFrom(ctx context.Context) *slog.Logger {
+	if log, ok := ctx.Value(loggerKey{}).(*slog.Logger); ok {
+		return log
+	}
+	return discardLogger
+}
+
+func durationMS(d time.Duration) float64 {
+	return float64(d.Microseconds()) / 1000
+}
@@ -0,0 +1,123 @@
+package matcher
+
+import (
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// Sources of a Setting.
+const (
+	SourceEnv     = "env"
+	SourceDotEnv  = ".env"
+	SourceDefault = "default"
+)
+
+// Setting is one environment setting as a run resolves it.
+type Setting struct {
+	Key    string `json:"key"`
+	Value  string `json:"value"`
+	Source string `json:"source"`
+}
+
+// settingDef is a setting read with Getenv or one of the env helpers.
+// def gives the value used when it is unset; secret values are masked.
+type settingDef struct {
+	key    string
+	def    func() string
+	secret bool
+}
+
+func constant(v string) func() string {
+	return func() string { return v }
+}
+
+// providerDefault is the default model of the configured provider.
+func providerDefault(i int) func() string {
+	return func() string {
+		provider := strings.ToLower(envString("RESUMEGPT_PROVIDER", ProviderOpenAI))
+		return providerModels[provider][i]
+	}
+}
+
+var settingDefs = []settingDef{
+	{key: "RESUMEGPT_PROVIDER", def: constant(ProviderOpenAI)},
+	{key: "RESUMEGPT_LLM_MODEL", def: providerDefault(0)},
+	{key: "RESUMEGPT_EMBED_MODEL", def: providerDefault(1)},
+	{key: "RESUMEGPT_LLM_TEMPERATURE", def: constant("0.2")},
+	{key: "RESUMEGPT_JSON_SCHEMA", def: constant("true")},
+	{key: "RESUMEGPT_REQUIRE_OPENAI", def: constant("false")},
+	{key: "OPENAI_API_KEY", def: constant(""), secret: true},
+	{key: "OPENAI_BASE_URL", def: constant("https://api.openai.com/v1")},
+	{key: "AZURE_OPENAI_ENDPOINT", def: constant("")},
+	{key: "AZURE_OPENAI_API_KEY", def: constant(""), secret: true},
+	{key: "AZURE_OPENAI_API_VERSION", def: constant("2024-10-21")},
+	{key: "AZURE_OPENAI_CHAT_DEPLOYMENT", def: func() string { return envString("RESUMEGPT_LLM_MODEL", providerDefault(0)()) }},
+	{key: "AZURE_OPENAI_EMBED_DEPLOYMENT", def: func() string { return envString("RESUMEGPT_EMBED_MODEL", providerDefault(1)()) }},
+	{key: "OLLAMA_HOST", def: constant("http://localhost:11434")},
+	{key: "RESUMEGPT_LOCAL_CORPUS", def: constant("")},
+	{key: "RESUMEGPT_WORKERS", def: func() string { return strconv.Itoa(runtime.NumCPU()) }},
+	{key: "RESUMEGPT_MIN_RESUME_WORDS", def: constant("50")},
+	{key: "RESUMEGPT_EMBED_BATCH", def: constant("96")},
+	{key: "RESUMEGPT_EMBED_CHUNK_WORDS", def: constant("2000")},
+	{key: "RESUMEGPT_EXPLAIN_TOPN", def: constant("20")},
+	{key: "RESUMEGPT_EXPLAIN_MAX_CHARS", def: constant("12000")},
+	{key: "RESUMEGPT_EXPLAIN_CONCURRENCY", def: constant("4")},
+	{key: "RESUMEGPT_OPENAI_RPM", def: constant("500")},
+	{key: "RESUMEGPT_OPENAI_MAX_RETRIES", def: constant("4")},
+	{key: "RESUMEGPT_OPENAI_BACKOFF_MS", def: constant("1000")},
+	{key: "RESUMEGPT_OPENAI_MAX_BACKOFF_MS", def: constant("60000")},
+	{key: "RESUMEGPT_MAX_COST_USD", def: constant("")},
+	{key: "RESUMEGPT_PRICES", def: constant("")},
+	{key: "RESUMEGPT_TAXONOMY", def: constant("")},
+	{key: "RESUMEGPT_PROFILES", def: constant("")},
+	{key: "RESUMEGPT_PROFILE", def: constant(DefaultProfile)},
+	{key: "RESUMEGPT_WEIGHTS", def: constant("")},
+	{key: "RESUMEGPT_RULES", def: constant("")},
+	{key: "RESUMEGPT_REDACT", def: constant(RedactPII)},
+	{key: "RESUMEGPT_FORMATS", def: constant("")},
+	{key: "RESUMEGPT_REPORT", def: constant("")},
+	{key: "RESUMEGPT_CACHE_DIR", def: CacheDir},
+	{key: "RESUMEGPT_TEXT_CACHE", def: constant("true")},
+	{key: "RESUMEGPT_EMBED_CACHE", def: constant("true")},
+	{key: "RESUMEGPT_HISTORY", def: constant("true")},
+	{key: "RESUMEGPT_HISTORY_DIR", def: HistoryDir},
+	{key: "RESUMEGPT_LOG_LEVEL", def: constant("info")},
+}
+
+// Settings lists every environment setting with the value a run uses and
+// whether it came from the environment, the .env file, the project or user
+// config file or the default.
+// Empty defaults mean off or built-in. API keys are masked.
+func Settings() []Setting {
+	LoadDotEnv()
+	out := make([]Setting, 0, len(settingDefs))
+	for _, d := range settingDefs {
+		s := Setting{Key: d.key, Value: d.def(), Source: SourceDefault}
+		if v := strings.TrimSpace(os.Getenv(d.key)); v != "" {
+			s.Value = v
+			s.Source = SourceEnv
+			if fromDotEnv(d.key) {
+				s.Source = SourceDotEnv
+			}
+		} else if v, src, ok := configSetting(d.key); ok {
+			s.Value = v
+			s.Source = src
+		}
+		if d.secret && s.Value != "" {
+			s.Value = maskSecret(s.Value)
+		}
+		out = append(out, s)
+	}
+	return out
+}
+
+// maskSecret keeps the last four characters of a key so it can be told
+// apart from another one.
+func maskSecret(v string) string {
+	if len(v) <= 8 {
+		return "****"
+	}
+	return "****" + v[len(v)-4:]
+}
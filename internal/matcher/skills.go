@@ -0,0 +1,120 @@
+package matcher
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+)
+
+var redactWords = buildRedactWords()
+
+// skillMatcher finds skills in tokenized text on token boundaries. Multi-word
+// skills are matched as whole phrases and the longest phrase at a position wins,
+// so "objective-c" does not also count as "c".
+type skillMatcher struct {
+	phrases map[string][]skillPhrase
+}
+
+type skillPhrase struct {
+	tokens    []string
+	canonical string
+}
+
+func newSkillMatcher(tax *skillTaxonomy, skills []string) *skillMatcher {
+	m := &skillMatcher{phrases: map[string][]skillPhrase{}}
+	seen := map[string]bool{}
+	for _, s := range skills {
+		canonical := tax.canonical(s)
+		if canonical == "" {
+			continue
+		}
+		m.add(canonical, canonical, seen)
+		for _, alias := range tax.aliases[canonical] {
+			m.add(alias, canonical, seen)
+		}
+	}
+	for first := range m.phrases {
+		list := m.phrases[first]
+		sort.SliceStable(list, func(i, j int) bool {
+			return len(list[i].tokens) > len(list[j].tokens)
+		})
+	}
+	return m
+}
+
+func (m *skillMatcher) add(phrase, canonical string, seen map[string]bool) {
+	tokens := skillTokens(phrase)
+	if len(tokens) == 0 {
+		return
+	}
+	key := strings.Join(tokens, " ")
+	if seen[key] {
+		return
+	}
+	seen[key] = true
+	m.phrases[tokens[0]] = append(m.phrases[tokens[0]], skillPhrase{tokens: tokens, canonical: canonical})
+}
+
+// match returns the canonical skills found in an already normalized token list.
+func (m *skillMatcher) match(tokens []string) map[string]bool {
+	found := map[string]bool{}
+	for i := 0; i < len(tokens); {
+		matched := 0
+		for _, p := range m.phrases[tokens[i]] {
+			if hasTokensAt(tokens, i, p.tokens) {
+				found[p.canonical] = true
+				matched = len(p.tokens)
+				break
+			}
+		}
+		if matched == 0 {
+			matched = 1
+		}
+		i += matched
+	}
+	return found
+}
+
+func hasTokensAt(tokens []string, at int, want []string) bool {
+	if at+len(want) > len(tokens) {
+		return false
+	}
+	for k, w := range want {
+		if tokens[at+k] != w {
+			return false
+		}
+	}
+	return true
+}
+
+// rAndDRe matches "R&D", which would otherwise leave the token "r" and count
+// as the R language; it is rewritten to "rnd".
+var rAndDRe = regexp.MustCompile(`(?i)\br\s*&\s*d\b|\br\s+and\s+d\b`)
+
+// skillTokens tokenizes text the same way normalizeText does, without the
+// email/phone redaction, so skill phrases line up with normalized documents.
+func skillTokens(text string) []string {
+	t := rAndDRe.ReplaceAllString(strings.ToLower(text), "rnd")
+	fields := strings.Fields(nonWordRe.ReplaceAllString(t, " "))
+	out := make([]string, 0, len(fields))
+	for i, tok := range fields {
+		if stopwords[tok] {
+			continue
+		}
+		if redactWords[tok] && (i == 0 || fields[i-1] != redactKeep[tok]) {
+			continue
+		}
+		out = append(out, tok)
+	}
+	return out
+}
+
+func buildRedactWords() map[string]bool {
+	words := map[string]bool{}
+	for _, term := range redactTerms {
+		if !strings.Contains(term, " ") {
+			words[term] = true
+		}
+	}
+	return words
+}
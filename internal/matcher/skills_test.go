@@ -0,0 +1,103 @@
+package matcher
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestSkillsInText(t *testing.T) {
+	tests := []struct {
+		name   string
+		text   string
+		skills []string
+		want   []string
+	}{
+		{"java is not javascript", "Senior JavaScript developer", []string{"java", "javascript"}, []string{"javascript"}},
+		{"javascript is not java", "Built services in Java 17", []string{"java", "javascript"}, []string{"java"}},
+		{"go is not google", "Worked at Google on search ranking", []string{"go"}, nil},
+		{"go on its own", "Backend in Go and Python", []string{"go", "python"}, []string{"go", "python"}},
+		{"alias golang", "Golang microservices", []string{"go"}, []string{"go"}},
+		{"alias k8s", "Deployed to k8s clusters", []string{"kubernetes"}, []string{"kubernetes"}},
+		{"alias maps to canonical skill", "Tuned Postgres queries", []string{"postgres"}, []string{"postgresql"}},
+		{"dotted alias", "Frontend with React.js", []string{"react"}, []string{"react"}},
+		{"longest phrase wins", "Objective-C and Swift", []string{"c", "objective-c", "swift"}, []string{"objective-c", "swift"}},
+		{"phrase and its word", "Machine learning and deep learning", []string{"machine learning", "deep learning"}, []string{"deep learning", "machine learning"}},
+		{"c next to objective-c", "C, Objective-C", []string{"c", "objective-c"}, []string{"c", "objective-c"}},
+		{"symbols kept", "C++ and C# on .NET", []string{"c", "c++", "c#"}, []string{"c#", "c++"}},
+		{"no partial phrase", "Machine shop operator", []string{"machine learning"}, nil},
+		{"react native is not react", "Mobile apps in React Native", []string{"react", "react native"}, []string{"react native"}},
+		{"react next to react native", "React, React-Native", []string{"react", "react native"}, []string{"react", "react native"}},
+		{"native alone is redacted", "Native English speaker", []string{"react native"}, nil},
+		{"mode is not mode analytics", "Worked in incident mode", []string{"mode analytics"}, nil},
+		{"mode analytics", "Dashboards in Mode Analytics", []string{"mode analytics"}, []string{"mode analytics"}},
+		{"r&d is not r", "Led the R&D team", []string{"r"}, nil},
+		{"r & d is not r", "R & D and R and D budgets", []string{"r"}, nil},
+		{"r language", "Statistics in R and Python", []string{"r", "python"}, []string{"python", "r"}},
+		{"empty and unknown skills", "Python", []string{"", "python"}, []string{"python"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			found := skillsInText(defaultTaxonomy, normalizeText(tt.text), tt.skills)
+			var got []string
+			for s := range found {
+				got = append(got, s)
+			}
+			sort.Strings(got)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("skillsInText(%q, %v) = %v, want %v", tt.text, tt.skills, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCanonicalSkill(t *testing.T) {
+	tests := map[string]string{
+		"golang":       "go",
+		"K8s":          "kubernetes",
+		"ReactJS":      "react",
+		"cpp":          "c++",
+		"JS":           "javascript",
+		"psql":         "postgresql",
+		"python":       "python",
+		"React Native": "react native",
+		"react-native": "react native",
+	}
+	for in, want := range tests {
+		if got := defaultTaxonomy.canonical(in); got != want {
+			t.Errorf("canonical(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestSkillTokensKeepsReactNative(t *testing.T) {
+	got := skillTokens("React Native developer, native speaker")
+	want := []string{"react", "native", "developer", "speaker"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("skillTokens = %v, want %v", got, want)
+	}
+}
+
+func TestRedactPIIKeepsReactNative(t *testing.T) {
+	got := normalizeText("React Native and react-native apps; Native American")
+	if want := "react native react native apps american"; got != want {
+		t.Errorf("normalizeText = %q, want %q", got, want)
+	}
+}
+
+func TestJDSkills(t *testing.T) {
+	jdInfo := JDExtract{SkillsMust: []string{"Golang", "K8s"}, SkillsNice: []string{"ReactJS"}}
+	must, nice, other, fromText := jdSkills(defaultTaxonomy, jdInfo, "", nil)
+	if fromText {
+		t.Error("model skills were replaced by the jd text")
+	}
+	if !reflect.DeepEqual(must, []string{"go", "kubernetes"}) || !reflect.DeepEqual(nice, []string{"react"}) || len(other) != 0 {
+		t.Errorf("jdSkills = %v, %v, %v, want [go kubernetes], [react], []", must, nice, other)
+	}
+
+	fallback := []string{"python"}
+	_, _, other, fromText = jdSkills(defaultTaxonomy, JDExtract{}, "Python developer", fallback)
+	if !fromText || !reflect.DeepEqual(other, fallback) {
+		t.Errorf("no model skills: other = %v, fromText = %v, want %v, true", other, fromText, fallback)
+	}
+}
@@ -0,0 +1,129 @@
+package matcher
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// SkippedFile is a resume that was not scored (Output.Skipped) or that was
+// scored but looks unreliable (Output.Flagged), with the reason.
+type SkippedFile struct {
+	Path   string `json:"path"`
+	Reason string `json:"reason"`
+}
+
+// minResumeWords is the word count under which extracted text is flagged as
+// suspiciously short, which usually means a scanned PDF or a bad conversion.
+func minResumeWords() int {
+	return envInt("RESUMEGPT_MIN_RESUME_WORDS", 50)
+}
+
+// loadResumes extracts the text of every resume file on the worker pool.
+// Files that cannot be read or contain no text are returned as skipped instead
+// of being dropped. Everything keeps the order of files. The only error is
+// ctx's, when it is canceled.
+func loadResumes(ctx context.Context, files []string, workers int, prog *progressReporter) ([]resumeDoc, []SkippedFile, []SkippedFile, error) {
+	type loaded struct {
+		doc  resumeDoc
+		skip string
+	}
+	minWords := minResumeWords()
+	all := make([]loaded, len(files))
+	prog.start(StageExtract, len(files))
+	parallelFor(len(files), workers, func(i int) {
+		if ctx.Err() != nil {
+			return
+		}
+		path := files[i]
+		defer prog.step(path)
+		raw, norm, err := extractTextCached(path)
+		if err != nil {
+			all[i].skip = skipReason(path, err)
+			return
+		}
+		words := len(strings.Fields(raw))
+		if words == 0 {
+			all[i].skip = emptyTextReason(path)
+			return
+		}
+		all[i].doc = resumeDoc{
+			Path:     path,
+			Name:     strings.TrimSuffix(filepath.Base(path), filepath.Ext(path)),
+			Raw:      raw,
+			Redacted: redactForProvider(raw),
+			Norm:     norm,
+		}
+		if words < minWords {
+			all[i].doc.Warning = fmt.Sprintf("only %d words of text extracted", words)
+		}
+	})
+	if err := ctx.Err(); err != nil {
+		return nil, nil, nil, err
+	}
+
+	docs := make([]resumeDoc, 0, len(files))
+	skipped := []SkippedFile{}
+	flagged := []SkippedFile{}
+	log := logFrom(ctx)
+	for i, l := range all {
+		if l.skip != "" {
+			log.Warn("resume skipped", "file", files[i], "reason", l.skip)
+			skipped = append(skipped, SkippedFile{Path: files[i], Reason: l.skip})
+			continue
+		}
+		if l.doc.Warning != "" {
+			log.Warn("resume flagged", "file", files[i], "reason", l.doc.Warning)
+			flagged = append(flagged, SkippedFile{Path: files[i], Reason: l.doc.Warning})
+		}
+		docs = append(docs, l.doc)
+	}
+	return docs, skipped, flagged, nil
+}
+
+func skipReason(path string, err error) string {
+	msg := err.Error()
+	lower := strings.ToLower(msg)
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".pdf":
+		if strings.Contains(lower, "encrypt") || strings.Contains(lower, "password") {
+			return "encrypted PDF: " + msg
+		}
+		return "unreadable PDF: " + msg
+	case ".docx":
+		return "corrupt or unreadable DOCX: " + msg
+	}
+	return msg
+}
+
+func emptyTextReason(path string) string {
+	if strings.ToLower(filepath.Ext(path)) == ".pdf" {
+		return "no extractable text (scanned or image-only PDF?)"
+	}
+	return "no text"
+}
+
+// skippedPath is where the skipped report goes: skipped.csv next to the
+// results file.
+func skippedPath(outPath string) string {
+	return filepath.Join(filepath.Dir(outPath), "skipped.csv")
+}
+
+func writeSkippedCSV(path string, skipped []SkippedFile) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	w := csv.NewWriter(f)
+	_ = w.Write([]string{"File", "Reason"})
+	for _, s := range skipped {
+		_ = w.Write([]string{s.Path, s.Reason})
+	}
+	w.Flush()
+	return w.Error()
+}
@@ -0,0 +1,202 @@
+package matcher
+
+import (
+	_ "embed"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+//go:embed taxonomy/default.yaml
+var defaultTaxonomyData []byte
+
+var ErrLoadTaxonomy = errors.New("failed to load skill taxonomy")
+
+// TaxonomyFile is the on-disk shape of a skill taxonomy. Both YAML and JSON
+// files are accepted.
+type TaxonomyFile struct {
+	Version      string          `yaml:"version" json:"version"`
+	ParentCredit *float64        `yaml:"parent_credit" json:"parent_credit"`
+	Categories   []SkillCategory `yaml:"categories" json:"categories"`
+}
+
+type SkillCategory struct {
+	Name   string       `yaml:"name" json:"name"`
+	Skills []SkillEntry `yaml:"skills" json:"skills"`
+}
+
+type SkillEntry struct {
+	Name    string   `yaml:"name" json:"name"`
+	Aliases []string `yaml:"aliases" json:"aliases"`
+	Parents []string `yaml:"parents" json:"parents"`
+}
+
+type skillTaxonomy struct {
+	version      string
+	parentCredit float64
+	skills       []string
+	aliases      map[string][]string
+	aliasIndex   map[string]string
+	children     map[string][]string
+	category     map[string]string
+	matcher      *skillMatcher
+}
+
+var defaultTaxonomy = mustParseTaxonomy(defaultTaxonomyData)
+
+// loadTaxonomy reads the taxonomy at path, falling back to RESUMEGPT_TAXONOMY
+// and then to the embedded default.
+func loadTaxonomy(path string) (*skillTaxonomy, error) {
+	path = strings.TrimSpace(path)
+	if path == "" {
+		path = strings.TrimSpace(Getenv("RESUMEGPT_TAXONOMY"))
+	}
+	if path == "" {
+		return defaultTaxonomy, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrLoadTaxonomy, err)
+	}
+	tax, err := parseTaxonomy(data)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s: %v", ErrLoadTaxonomy, path, err)
+	}
+	return tax, nil
+}
+
+func mustParseTaxonomy(data []byte) *skillTaxonomy {
+	tax, err := parseTaxonomy(data)
+	if err != nil {
+		panic(fmt.Sprintf("matcher: embedded taxonomy: %v", err))
+	}
+	return tax
+}
+
+func parseTaxonomy(data []byte) (*skillTaxonomy, error) {
+	var file TaxonomyFile
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, err
+	}
+
+	tax := &skillTaxonomy{
+		version:      strings.TrimSpace(file.Version),
+		parentCredit: 0.5,
+		aliases:      map[string][]string{},
+		aliasIndex:   map[string]string{},
+		children:     map[string][]string{},
+		category:     map[string]string{},
+	}
+	if file.ParentCredit != nil {
+		tax.parentCredit = clamp(*file.ParentCredit, 0, 1)
+	}
+
+	parents := map[string][]string{}
+	for _, cat := range file.Categories {
+		for _, entry := range cat.Skills {
+			name := strings.ToLower(strings.TrimSpace(entry.Name))
+			if name == "" {
+				return nil, fmt.Errorf("category %q: skill without a name", cat.Name)
+			}
+			key := strings.Join(skillTokens(name), " ")
+			if key == "" {
+				return nil, fmt.Errorf("skill %q has no matchable words", entry.Name)
+			}
+			if existing, ok := tax.aliasIndex[key]; ok && existing != name {
+				return nil, fmt.Errorf("skill %q collides with %q", entry.Name, existing)
+			}
+			if _, ok := tax.category[name]; !ok {
+				tax.skills = append(tax.skills, name)
+			}
+			tax.aliasIndex[key] = name
+			tax.category[name] = strings.TrimSpace(cat.Name)
+			for _, alias := range entry.Aliases {
+				alias = strings.ToLower(strings.TrimSpace(alias))
+				aliasKey := strings.Join(skillTokens(alias), " ")
+				if aliasKey == "" {
+					continue
+				}
+				tax.aliasIndex[aliasKey] = name
+				tax.aliases[name] = append(tax.aliases[name], alias)
+			}
+			for _, p := range entry.Parents {
+				p = strings.ToLower(strings.TrimSpace(p))
+				if p != "" && p != name {
+					parents[name] = append(parents[name], p)
+				}
+			}
+		}
+	}
+	if len(tax.skills) == 0 {
+		return nil, errors.New("taxonomy has no skills")
+	}
+
+	for child, list := range parents {
+		for _, p := range list {
+			if _, ok := tax.category[p]; !ok {
+				return nil, fmt.Errorf("skill %q has unknown parent %q", child, p)
+			}
+			tax.children[p] = append(tax.children[p], child)
+		}
+	}
+	for p := range tax.children {
+		sort.Strings(tax.children[p])
+	}
+
+	tax.matcher = newSkillMatcher(tax, tax.skills)
+	return tax, nil
+}
+
+// canonical lowercases a skill name and resolves known aliases, so "Golang"
+// and "go" compare equal.
+func (t *skillTaxonomy) canonical(skill string) string {
+	s := strings.ToLower(strings.TrimSpace(skill))
+	if s == "" {
+		return ""
+	}
+	if c, ok := t.aliasIndex[strings.Join(skillTokens(s), " ")]; ok {
+		return c
+	}
+	return s
+}
+
+func (t *skillTaxonomy) canonicalList(items []string) []string {
+	out := make([]string, 0, len(items))
+	seen := map[string]bool{}
+	for _, item := range items {
+		c := t.canonical(item)
+		if c == "" || seen[c] {
+			continue
+		}
+		seen[c] = true
+		out = append(out, c)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// credit is 1 when the resume has the skill itself and parentCredit when it
+// only has one of the skill's children (for example "aws" for "cloud").
+func (t *skillTaxonomy) credit(resSkills map[string]bool, skill string) float64 {
+	if resSkills[skill] {
+		return 1
+	}
+	for _, child := range t.children[skill] {
+		if resSkills[child] {
+			return t.parentCredit
+		}
+	}
+	return 0
+}
+
+func (t *skillTaxonomy) coverage(resSkills map[string]bool, skills []string) float64 {
+	total := 0.0
+	for _, s := range skills {
+		total += t.credit(resSkills, s)
+	}
+	return total
+}
@@ -0,0 +1,188 @@
+package matcher
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// extractorVersion is part of every text cache key. Bump it whenever
+// extractText or normalizeText change their output so old entries are ignored.
+const extractorVersion = 1
+
+var ErrCache = errors.New("cache error")
+
+// CacheInfo describes one on-disk cache.
+type CacheInfo struct {
+	Dir     string `json:"dir"`
+	Entries int    `json:"entries"`
+	Bytes   int64  `json:"bytes"`
+}
+
+type textCacheEntry struct {
+	Version int    `json:"version"`
+	Raw     string `json:"raw"`
+	Norm    string `json:"norm"`
+}
+
+// CacheDir is the base directory of the on-disk caches: RESUMEGPT_CACHE_DIR,
+// or resumegpt under the user cache directory.
+func CacheDir() string {
+	if dir := strings.TrimSpace(Getenv("RESUMEGPT_CACHE_DIR")); dir != "" {
+		return dir
+	}
+	base, err := os.UserCacheDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(base, "resumegpt")
+}
+
+func textCacheDir() string {
+	base := CacheDir()
+	if base == "" {
+		return ""
+	}
+	return filepath.Join(base, "text")
+}
+
+// extractTextCached returns the raw and normalized text of path, reusing the
+// cached copy when a file with the same content was extracted before. Set
+// RESUMEGPT_TEXT_CACHE=0 to always extract.
+func extractTextCached(path string) (string, string, error) {
+	dir := textCacheDir()
+	if dir == "" || !envBool("RESUMEGPT_TEXT_CACHE", true) {
+		raw, err := extractText(path)
+		if err != nil {
+			return "", "", err
+		}
+		return raw, normalizeText(raw), nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", "", err
+	}
+	entryPath := textCachePath(dir, path, data)
+	if cached, ok := readTextCacheEntry(entryPath); ok {
+		return cached.Raw, cached.Norm, nil
+	}
+
+	raw, err := extractText(path)
+	if err != nil {
+		return "", "", err
+	}
+	entry := textCacheEntry{Version: extractorVersion, Raw: raw, Norm: normalizeText(raw)}
+	_ = writeFileAtomic(entryPath, entry)
+	return entry.Raw, entry.Norm, nil
+}
+
+// textCachePath keys an entry by the file content, its extension (which picks
+// the extractor) and the extractor version, so edited files miss the cache.
+func textCachePath(dir, path string, data []byte) string {
+	h := sha256.New()
+	fmt.Fprintf(h, "v%d\x00%s\x00", extractorVersion, strings.ToLower(filepath.Ext(path)))
+	h.Write(data)
+	key := hex.EncodeToString(h.Sum(nil))
+	return filepath.Join(dir, key[:2], key+".json")
+}
+
+func readTextCacheEntry(path string) (textCacheEntry, bool) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return textCacheEntry{}, false
+	}
+	var entry textCacheEntry
+	if err := json.Unmarshal(data, &entry); err != nil || entry.Version != extractorVersion {
+		return textCacheEntry{}, false
+	}
+	return entry, true
+}
+
+// writeFileAtomic writes v as JSON with writeBytesAtomic.
+func writeFileAtomic(path string, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return writeBytesAtomic(path, data)
+}
+
+// writeBytesAtomic writes through a temp file and a rename, so concurrent runs
+// never read a half-written entry.
+func writeBytesAtomic(path string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".tmp-*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return nil
+}
+
+// TextCacheInfo reports where the text cache lives and how big it is.
+func TextCacheInfo() (CacheInfo, error) {
+	return cacheInfo(textCacheDir())
+}
+
+// ClearTextCache deletes the text cache and returns what was removed.
+func ClearTextCache() (CacheInfo, error) {
+	return clearCache(textCacheDir())
+}
+
+func cacheInfo(dir string) (CacheInfo, error) {
+	info := CacheInfo{Dir: dir}
+	if dir == "" {
+		return info, fmt.Errorf("%w: no cache directory", ErrCache)
+	}
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || strings.HasPrefix(d.Name(), ".tmp-") {
+			return nil
+		}
+		fi, err := d.Info()
+		if err != nil {
+			return err
+		}
+		info.Entries++
+		info.Bytes += fi.Size()
+		return nil
+	})
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return info, fmt.Errorf("%w: %v", ErrCache, err)
+	}
+	return info, nil
+}
+
+func clearCache(dir string) (CacheInfo, error) {
+	info, err := cacheInfo(dir)
+	if err != nil {
+		return info, err
+	}
+	if err := os.RemoveAll(dir); err != nil {
+		return info, fmt.Errorf("%w: %v", ErrCache, err)
+	}
+	return info, nil
+}
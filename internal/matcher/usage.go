@@ -0,0 +1,180 @@
+package matcher
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Usage is the token count and estimated cost of one OpenAI mode run.
+type Usage struct {
+	Requests         int     `json:"requests"`
+	PromptTokens     int     `json:"promptTokens"`
+	CompletionTokens int     `json:"completionTokens"`
+	EmbeddingTokens  int     `json:"embeddingTokens"`
+	CostUSD          float64 `json:"costUsd"`
+	// Unpriced lists models missing from the price table; their tokens are
+	// counted but add nothing to CostUSD.
+	Unpriced  []string `json:"unpriced,omitempty"`
+	BudgetUSD float64  `json:"budgetUsd,omitempty"`
+	// ExplainSkipped counts explanations that were not requested because the
+	// budget had been reached.
+	ExplainSkipped int `json:"explainSkipped,omitempty"`
+}
+
+// ModelPrice is the USD price per million tokens of a model. Embedding models
+// only have an input price.
+type ModelPrice struct {
+	Input  float64 `yaml:"input" json:"input"`
+	Output float64 `yaml:"output" json:"output"`
+}
+
+// defaultPrices are list prices at the time of writing. Override or extend
+// them with a RESUMEGPT_PRICES file when they change.
+var defaultPrices = map[string]ModelPrice{
+	"gpt-4o":                 {Input: 2.50, Output: 10.00},
+	"gpt-4o-mini":            {Input: 0.15, Output: 0.60},
+	"gpt-4.1":                {Input: 2.00, Output: 8.00},
+	"gpt-4.1-mini":           {Input: 0.40, Output: 1.60},
+	"gpt-4.1-nano":           {Input: 0.10, Output: 0.40},
+	"text-embedding-3-large": {Input: 0.13},
+	"text-embedding-3-small": {Input: 0.02},
+	"text-embedding-ada-002": {Input: 0.10},
+}
+
+// loadPrices returns the default price table merged with the YAML or JSON
+// file at path ("model: {input: 2.5, output: 10}").
+func loadPrices(path string) (map[string]ModelPrice, error) {
+	prices := make(map[string]ModelPrice, len(defaultPrices))
+	for model, price := range defaultPrices {
+		prices[model] = price
+	}
+	if path == "" {
+		return prices, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("%w: price table: %v", ErrProvider, err)
+	}
+	var custom map[string]ModelPrice
+	if err := yaml.Unmarshal(data, &custom); err != nil {
+		return nil, fmt.Errorf("%w: price table %s: %v", ErrProvider, path, err)
+	}
+	for model, price := range custom {
+		if price.Input < 0 || price.Output < 0 {
+			return nil, fmt.Errorf("%w: price table %s: negative price for %s", ErrProvider, path, model)
+		}
+		prices[strings.ToLower(strings.TrimSpace(model))] = price
+	}
+	return prices, nil
+}
+
+// usageMeter adds up the tokens reported by every request of a run. It is
+// shared by a provider's chat and embedding calls; a nil meter records
+// nothing.
+type usageMeter struct {
+	mu     sync.Mutex
+	prices map[string]ModelPrice
+	models map[string]*modelTokens
+	usage  Usage
+}
+
+type modelTokens struct {
+	input  int
+	output int
+}
+
+func newUsageMeter(prices map[string]ModelPrice) *usageMeter {
+	return &usageMeter{prices: prices, models: map[string]*modelTokens{}}
+}
+
+func (m *usageMeter) addChat(model string, prompt, completion int) {
+	m.add(model, prompt, completion, false)
+}
+
+func (m *usageMeter) addEmbedding(model string, tokens int) {
+	m.add(model, tokens, 0, true)
+}
+
+func (m *usageMeter) add(model string, input, output int, embedding bool) {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.usage.Requests++
+	if embedding {
+		m.usage.EmbeddingTokens += input
+	} else {
+		m.usage.PromptTokens += input
+		m.usage.CompletionTokens += output
+	}
+	t := m.models[model]
+	if t == nil {
+		t = &modelTokens{}
+		m.models[model] = t
+	}
+	t.input += input
+	t.output += output
+}
+
+// cost is the estimated spend so far in USD.
+func (m *usageMeter) cost() float64 {
+	if m == nil {
+		return 0
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	total := 0.0
+	for model, t := range m.models {
+		if price, ok := m.price(model); ok {
+			total += (float64(t.input)*price.Input + float64(t.output)*price.Output) / 1e6
+		}
+	}
+	return total
+}
+
+// price looks a model up by the longest matching prefix, so dated snapshots
+// such as gpt-4o-2024-08-06 use the gpt-4o price and gpt-4o-mini keeps its own.
+func (m *usageMeter) price(model string) (ModelPrice, bool) {
+	model = strings.ToLower(model)
+	best := ""
+	for name := range m.prices {
+		if strings.HasPrefix(model, name) && len(name) > len(best) {
+			best = name
+		}
+	}
+	if best == "" {
+		return ModelPrice{}, false
+	}
+	return m.prices[best], true
+}
+
+func (m *usageMeter) snapshot() Usage {
+	cost := m.cost()
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	u := m.usage
+	u.CostUSD = cost
+	u.Unpriced = nil
+	for model, t := range m.models {
+		if _, ok := m.price(model); !ok && t.input+t.output > 0 {
+			u.Unpriced = append(u.Unpriced, model)
+		}
+	}
+	sort.Strings(u.Unpriced)
+	return u
+}
+
+func (m *usageMeter) skipExplain() {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	m.usage.ExplainSkipped++
+	m.mu.Unlock()
+}
@@ -19,6 +19,12 @@ func ReadWorkbookInputs(workbook string) (Input, error) {
     resumesPath, _ := f.GetCellValue("Inputs", "B2")
     topNStr, _ := f.GetCellValue("Inputs", "B3")
     outPath, _ := f.GetCellValue("Inputs", "B4")
+    taxonomyPath, _ := f.GetCellValue("Inputs", "B9")
+    profile, _ := f.GetCellValue("Inputs", "B10")
+    weights, _ := f.GetCellValue("Inputs", "B11")
+    rulesPath, _ := f.GetCellValue("Inputs", "B12")
+    knockoutMode, _ := f.GetCellValue("Inputs", "B13")
+    knockoutJD, _ := f.GetCellValue("Inputs", "B14")
 
     topN := 0
     if topNStr != "" {
@@ -30,9 +36,23 @@ func ReadWorkbookInputs(workbook string) (Input, error) {
     }
 
     return Input{
-        JDPath:     strings.TrimSpace(jdPath),
-        ResumesDir: strings.TrimSpace(resumesPath),
-        TopN:       topN,
-        OutPath:    strings.TrimSpace(outPath),
+        JDPath:         strings.TrimSpace(jdPath),
+        ResumesDir:     strings.TrimSpace(resumesPath),
+        TopN:           topN,
+        OutPath:        strings.TrimSpace(outPath),
+        TaxonomyPath:   strings.TrimSpace(taxonomyPath),
+        Profile:        strings.TrimSpace(profile),
+        Weights:        strings.TrimSpace(weights),
+        RulesPath:      strings.TrimSpace(rulesPath),
+        KnockoutMode:   strings.TrimSpace(knockoutMode),
+        KnockoutFromJD: isTruthy(knockoutJD),
     }, nil
-}
+}
+
+func isTruthy(value string) bool {
+    switch strings.ToLower(strings.TrimSpace(value)) {
+    case "1", "true", "yes", "y", "on":
+        return true
+    }
+    return false
+}
@@ -0,0 +1,90 @@
+package matcher
+
+import (
+	"context"
+	"runtime"
+	"sync"
+	"time"
+)
+
+// workerCount returns n when positive, otherwise RESUMEGPT_WORKERS or the
+// number of CPUs.
+func workerCount(n int) int {
+	if n > 0 {
+		return n
+	}
+	return envInt("RESUMEGPT_WORKERS", runtime.NumCPU())
+}
+
+// parallelFor calls fn for every index in [0, n) on up to workers goroutines.
+// Callers write into index i of a pre-sized slice so the output order does not
+// depend on scheduling.
+func parallelFor(n, workers int, fn func(i int)) {
+	if workers > n {
+		workers = n
+	}
+	if workers <= 1 {
+		for i := 0; i < n; i++ {
+			fn(i)
+		}
+		return
+	}
+
+	next := make(chan int)
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	for w := 0; w < workers; w++ {
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				fn(i)
+			}
+		}()
+	}
+	for i := 0; i < n; i++ {
+		next <- i
+	}
+	close(next)
+	wg.Wait()
+}
+
+// rateLimiter spaces calls evenly so that at most perMinute start in any
+// minute. A nil limiter does not wait.
+type rateLimiter struct {
+	mu       sync.Mutex
+	interval time.Duration
+	next     time.Time
+}
+
+func newRateLimiter(perMinute int) *rateLimiter {
+	if perMinute <= 0 {
+		return nil
+	}
+	return &rateLimiter{interval: time.Minute / time.Duration(perMinute)}
+}
+
+func (l *rateLimiter) wait(ctx context.Context) error {
+	if l == nil {
+		return nil
+	}
+	l.mu.Lock()
+	now := time.Now()
+	if l.next.Before(now) {
+		l.next = now
+	}
+	delay := l.next.Sub(now)
+	l.next = l.next.Add(l.interval)
+	l.mu.Unlock()
+
+	if delay <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
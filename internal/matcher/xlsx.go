@@ -0,0 +1,359 @@
+package matcher
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// Sheets written by the XLSX writer. The macro workbook reads Results by name,
+// so updateResultsWorkbook reuses the existing sheet.
+const (
+	resultsSheet = "Results"
+	runSheet     = "Run"
+)
+
+// Column positions on the Results sheet, see resultColumns.
+const (
+	scoreCol      = 3
+	fileCol       = 7
+	breakdownCol  = 8
+	knockedOutCol = breakdownCol + len(breakdownColumns)
+)
+
+// writeResultsXLSX writes a new workbook with a Results sheet holding the same
+// columns as results.csv and a Run sheet with the run parameters and the JD
+// extract.
+func writeResultsXLSX(path string, doc ResultsDocument) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	f := excelize.NewFile()
+	defer f.Close()
+	if err := f.SetSheetName("Sheet1", resultsSheet); err != nil {
+		return err
+	}
+	if err := fillResultsWorkbook(f, doc); err != nil {
+		return err
+	}
+	return f.SaveAs(path)
+}
+
+// updateResultsWorkbook rewrites the Results and Run sheets of an existing
+// workbook such as ResumeMatcher.xlsm and leaves its other sheets and macros
+// alone. Excel must not have the file open.
+func updateResultsWorkbook(path string, doc ResultsDocument) error {
+	f, err := excelize.OpenFile(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if err := fillResultsWorkbook(f, doc); err != nil {
+		return err
+	}
+	return f.Save()
+}
+
+func fillResultsWorkbook(f *excelize.File, doc ResultsDocument) error {
+	for _, sheet := range []string{resultsSheet, runSheet} {
+		if err := resetSheet(f, sheet); err != nil {
+			return err
+		}
+	}
+	if err := writeResultsSheet(f, doc.Results); err != nil {
+		return err
+	}
+	if err := writeRunSheet(f, doc); err != nil {
+		return err
+	}
+	idx, err := f.GetSheetIndex(resultsSheet)
+	if err != nil {
+		return err
+	}
+	f.SetActiveSheet(idx)
+	return nil
+}
+
+// resetSheet empties sheet, creating it when missing. Removing the rows
+// rather than the sheet keeps its position and its VBA code name.
+func resetSheet(f *excelize.File, sheet string) error {
+	idx, err := f.GetSheetIndex(sheet)
+	if err != nil {
+		return err
+	}
+	if idx < 0 {
+		_, err := f.NewSheet(sheet)
+		return err
+	}
+	rows, err := f.GetRows(sheet)
+	if err != nil {
+		return err
+	}
+	for r := len(rows); r > 0; r-- {
+		if err := f.RemoveRow(sheet, r); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeResultsSheet(f *excelize.File, results []Result) error {
+	header := resultColumns()
+	if err := f.SetSheetRow(resultsSheet, "A1", &header); err != nil {
+		return err
+	}
+	for i, r := range results {
+		row := []any{r.Rank, r.Candidate, r.Score, r.Strengths, r.Weaknesses, r.Explanation, r.File}
+		row = append(row, r.Breakdown.cells()...)
+		row = append(row, r.KnockedOut, strings.Join(r.FailedRules, "; "), r.Warning)
+		cell, _ := excelize.CoordinatesToCellName(1, i+2)
+		if err := f.SetSheetRow(resultsSheet, cell, &row); err != nil {
+			return err
+		}
+		if r.File != "" {
+			link, _ := excelize.CoordinatesToCellName(fileCol, i+2)
+			target, err := filepath.Abs(r.File)
+			if err != nil {
+				target = r.File
+			}
+			if err := f.SetCellHyperLink(resultsSheet, link, target, "External"); err != nil {
+				return err
+			}
+		}
+	}
+	return formatResultsSheet(f, len(header), len(results))
+}
+
+// formatResultsSheet styles the Results sheet: a bold frozen header with an
+// autofilter, number formats on the score columns, a color scale on Score and
+// grayed-out knocked out candidates.
+func formatResultsSheet(f *excelize.File, cols, rows int) error {
+	lastCol, _ := excelize.ColumnNumberToName(cols)
+	lastRow := rows + 1
+	headerStyle, err := f.NewStyle(&excelize.Style{
+		Font:      &excelize.Font{Bold: true},
+		Fill:      excelize.Fill{Type: "pattern", Pattern: 1, Color: []string{"D9E1F2"}},
+		Border:    []excelize.Border{{Type: "bottom", Color: "8EA9DB", Style: 1}},
+		Alignment: &excelize.Alignment{Vertical: "center"},
+	})
+	if err != nil {
+		return err
+	}
+	if err := f.SetCellStyle(resultsSheet, "A1", lastCol+"1", headerStyle); err != nil {
+		return err
+	}
+	if err := f.SetPanes(resultsSheet, &excelize.Panes{
+		Freeze:      true,
+		YSplit:      1,
+		TopLeftCell: "A2",
+		ActivePane:  "bottomLeft",
+	}); err != nil {
+		return err
+	}
+	// AutoFilter replaces the sheet properties, so put back the code name the
+	// macro workbook's VBA project knows the sheet by.
+	props, err := f.GetSheetProps(resultsSheet)
+	if err != nil {
+		return err
+	}
+	if err := f.AutoFilter(resultsSheet, fmt.Sprintf("A1:%s%d", lastCol, lastRow), nil); err != nil {
+		return err
+	}
+	if err := f.SetSheetProps(resultsSheet, &excelize.SheetPropsOptions{CodeName: props.CodeName}); err != nil {
+		return err
+	}
+
+	widths := map[int]float64{2: 28, 4: 40, 5: 40, 6: 48, fileCol: 40}
+	for c := breakdownCol + 15; c < knockedOutCol; c++ {
+		widths[c] = 30
+	}
+	widths[knockedOutCol+1] = 30
+	widths[knockedOutCol+2] = 40
+	for c := 1; c <= cols; c++ {
+		name, _ := excelize.ColumnNumberToName(c)
+		width, ok := widths[c]
+		if !ok {
+			width = 12
+		}
+		if err := f.SetColWidth(resultsSheet, name, name, width); err != nil {
+			return err
+		}
+	}
+	if rows == 0 {
+		return nil
+	}
+
+	points, err := f.NewStyle(&excelize.Style{NumFmt: 2})
+	if err != nil {
+		return err
+	}
+	ratio := "0.0000"
+	ratios, err := f.NewStyle(&excelize.Style{CustomNumFmt: &ratio})
+	if err != nil {
+		return err
+	}
+	link, err := f.NewStyle(&excelize.Style{Font: &excelize.Font{Color: "0563C1", Underline: "single"}})
+	if err != nil {
+		return err
+	}
+	setColumnStyle := func(col, style int) error {
+		top, _ := excelize.CoordinatesToCellName(col, 2)
+		bottom, _ := excelize.CoordinatesToCellName(col, lastRow)
+		return f.SetCellStyle(resultsSheet, top, bottom, style)
+	}
+	if err := setColumnStyle(scoreCol, points); err != nil {
+		return err
+	}
+	if err := setColumnStyle(fileCol, link); err != nil {
+		return err
+	}
+	for c := breakdownCol; c < breakdownCol+15; c++ {
+		style := ratios
+		if (c-breakdownCol)%3 == 2 {
+			style = points
+		}
+		if err := setColumnStyle(c, style); err != nil {
+			return err
+		}
+	}
+
+	scoreName, _ := excelize.ColumnNumberToName(scoreCol)
+	if err := f.SetConditionalFormat(resultsSheet, fmt.Sprintf("%s2:%s%d", scoreName, scoreName, lastRow), []excelize.ConditionalFormatOptions{{
+		Type:     "3_color_scale",
+		Criteria: "=",
+		MinType:  "num",
+		MidType:  "num",
+		MaxType:  "num",
+		MinValue: "0",
+		MidValue: "50",
+		MaxValue: "100",
+		MinColor: "#F8696B",
+		MidColor: "#FFEB84",
+		MaxColor: "#63BE7B",
+	}}); err != nil {
+		return err
+	}
+	knockedOut, err := f.NewConditionalStyle(&excelize.Style{Font: &excelize.Font{Color: "808080", Italic: true}})
+	if err != nil {
+		return err
+	}
+	koName, _ := excelize.ColumnNumberToName(knockedOutCol)
+	return f.SetConditionalFormat(resultsSheet, fmt.Sprintf("A2:%s%d", lastCol, lastRow), []excelize.ConditionalFormatOptions{{
+		Type:     "formula",
+		Criteria: fmt.Sprintf("$%s2=TRUE", koName),
+		Format:   knockedOut,
+	}})
+}
+
+// writeRunSheet lists the run parameters and the JD extract as label/value
+// rows.
+func writeRunSheet(f *excelize.File, doc ResultsDocument) error {
+	type entry struct {
+		label string
+		value any
+	}
+	rows := []entry{
+		{"Run", nil},
+		{"Generated", doc.GeneratedAt.Format("2006-01-02 15:04:05 UTC")},
+		{"Schema", fmt.Sprintf("%s v%d", doc.Schema, doc.SchemaVersion)},
+		{"Job description", doc.JDPath},
+		{"Resumes folder", doc.ResumesDir},
+		{"Top N", doc.TopN},
+		{"Mode", doc.Mode},
+	}
+	if doc.Provider != "" {
+		rows = append(rows, entry{"Provider", doc.Provider})
+	}
+	rows = append(rows,
+		entry{"Profile", doc.Profile},
+		entry{"Similarity weight", doc.Weights.Similarity},
+		entry{"Must weight", doc.Weights.Must},
+		entry{"Nice weight", doc.Weights.Nice},
+		entry{"Skills weight", doc.Weights.Skills},
+		entry{"Experience weight", doc.Weights.Experience},
+		entry{"Taxonomy", doc.Taxonomy},
+		entry{"Knockout mode", doc.KnockoutMode},
+	)
+	if len(doc.Rules) > 0 {
+		names := make([]string, 0, len(doc.Rules))
+		for _, r := range doc.Rules {
+			names = append(names, r.Name)
+		}
+		rows = append(rows, entry{"Knockout rules", strings.Join(names, "\n")})
+	}
+	rows = append(rows,
+		entry{"Excluded", doc.Excluded},
+		entry{"Resumes found", doc.Total},
+		entry{"Ranked", len(doc.Results)},
+		entry{"Skipped", len(doc.Skipped)},
+		entry{"Flagged", len(doc.Flagged)},
+	)
+	if u := doc.Usage; u != nil {
+		rows = append(rows,
+			entry{"Requests", u.Requests},
+			entry{"Prompt tokens", u.PromptTokens},
+			entry{"Completion tokens", u.CompletionTokens},
+			entry{"Embedding tokens", u.EmbeddingTokens},
+			entry{"Estimated cost (USD)", u.CostUSD},
+		)
+	}
+	rows = append(rows, entry{}, entry{"Job description extract", nil})
+	if jd := doc.JDInfo; jd != nil {
+		rows = append(rows,
+			entry{"Role title", jd.RoleTitle},
+			entry{"Must-have skills", strings.Join(jd.SkillsMust, ", ")},
+			entry{"Nice-to-have skills", strings.Join(jd.SkillsNice, ", ")},
+			entry{"Other skills", strings.Join(jd.SkillsOther, ", ")},
+			entry{"Minimum years", jd.YearsExperienceMin},
+			entry{"Education", strings.Join(jd.Education, "\n")},
+			entry{"Certifications", strings.Join(jd.Certifications, "\n")},
+			entry{"Titles", strings.Join(jd.Titles, "\n")},
+			entry{"Responsibilities", strings.Join(jd.Responsibilities, "\n")},
+		)
+	}
+
+	section, err := f.NewStyle(&excelize.Style{Font: &excelize.Font{Bold: true, Size: 12}})
+	if err != nil {
+		return err
+	}
+	label, err := f.NewStyle(&excelize.Style{Font: &excelize.Font{Bold: true}, Alignment: &excelize.Alignment{Vertical: "top"}})
+	if err != nil {
+		return err
+	}
+	value, err := f.NewStyle(&excelize.Style{Alignment: &excelize.Alignment{Horizontal: "left", Vertical: "top", WrapText: true}})
+	if err != nil {
+		return err
+	}
+	for i, e := range rows {
+		labelCell, _ := excelize.CoordinatesToCellName(1, i+1)
+		valueCell, _ := excelize.CoordinatesToCellName(2, i+1)
+		if e.label == "" {
+			continue
+		}
+		if err := f.SetCellValue(runSheet, labelCell, e.label); err != nil {
+			return err
+		}
+		if e.value == nil {
+			if err := f.SetCellStyle(runSheet, labelCell, labelCell, section); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := f.SetCellValue(runSheet, valueCell, e.value); err != nil {
+			return err
+		}
+		if err := f.SetCellStyle(runSheet, labelCell, labelCell, label); err != nil {
+			return err
+		}
+		if err := f.SetCellStyle(runSheet, valueCell, valueCell, value); err != nil {
+			return err
+		}
+	}
+	if err := f.SetColWidth(runSheet, "A", "A", 26); err != nil {
+		return err
+	}
+	return f.SetColWidth(runSheet, "B", "B", 80)
+}
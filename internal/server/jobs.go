@@ -0,0 +1,238 @@
+package server
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"os"
+	"sync"
+	"time"
+
+	"resume-gpt/internal/matcher"
+)
+
+// Job kinds, one per submitting endpoint.
+const (
+	KindRun          = "run"
+	KindRunHeuristic = "run-heuristic"
+	KindEvaluate     = "evaluate"
+	KindMatchJobs    = "match-jobs"
+)
+
+// Job statuses. Succeeded, failed and canceled are final.
+const (
+	StatusQueued    = "queued"
+	StatusRunning   = "running"
+	StatusSucceeded = "succeeded"
+	StatusFailed    = "failed"
+	StatusCanceled  = "canceled"
+)
+
+// JobStatus is what the jobs endpoints return. Result is a matcher.Output
+// for runs, a matcher.ResumeAnalysis for evaluations and a
+// matcher.JobsOutput for job matches; it is only set once
+// the job succeeded and only when a single job is fetched.
+type JobStatus struct {
+	ID       string            `json:"id"`
+	Kind     string            `json:"kind"`
+	Status   string            `json:"status"`
+	Created  time.Time         `json:"created"`
+	Started  *time.Time        `json:"started,omitempty"`
+	Finished *time.Time        `json:"finished,omitempty"`
+	Progress *matcher.Progress `json:"progress,omitempty"`
+	// Code names the matcher error of a failed job, e.g. "missing_jd".
+	Code   string `json:"code,omitempty"`
+	Error  string `json:"error,omitempty"`
+	Result any    `json:"result,omitempty"`
+}
+
+// jobFunc does the work of a job, reporting progress through progress.
+type jobFunc func(ctx context.Context, progress matcher.ProgressFunc) (any, error)
+
+type job struct {
+	dir  string
+	stop context.CancelFunc
+
+	mu     sync.Mutex
+	status JobStatus
+}
+
+// submit queues fn as a new job of kind. dir is the job's folder, removed
+// when the job is dropped.
+func (s *Server) submit(id, kind, dir string, fn jobFunc) *job {
+	ctx, stop := context.WithCancel(s.ctx)
+	j := &job{
+		dir:    dir,
+		stop:   stop,
+		status: JobStatus{ID: id, Kind: kind, Status: StatusQueued, Created: time.Now().UTC()},
+	}
+	s.mu.Lock()
+	s.jobs[id] = j
+	s.order = append(s.order, id)
+	s.mu.Unlock()
+	s.log.Info("job queued", "job", id, "kind", kind)
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		defer stop()
+		select {
+		case s.slots <- struct{}{}:
+		case <-ctx.Done():
+			j.finish(nil, ctx.Err())
+			s.jobDone(j)
+			return
+		}
+		defer func() { <-s.slots }()
+		j.setRunning()
+		result, err := fn(ctx, j.setProgress)
+		j.finish(result, err)
+		s.jobDone(j)
+	}()
+	return j
+}
+
+func (s *Server) jobDone(j *job) {
+	st := j.snapshot(false)
+	attrs := []any{"job", st.ID, "kind", st.Kind, "status", st.Status}
+	if st.Started != nil {
+		attrs = append(attrs, "duration_ms", float64(st.Finished.Sub(*st.Started).Microseconds())/1000)
+	}
+	if st.Error != "" {
+		attrs = append(attrs, "code", st.Code, "error", st.Error)
+	}
+	s.log.Info("job finished", attrs...)
+	s.prune()
+}
+
+// prune drops the oldest finished jobs beyond KeepJobs.
+func (s *Server) prune() {
+	s.mu.Lock()
+	var finished []string
+	for _, id := range s.order {
+		if s.jobs[id].finished() {
+			finished = append(finished, id)
+		}
+	}
+	s.mu.Unlock()
+	for len(finished) > s.cfg.KeepJobs {
+		s.remove(finished[0])
+		finished = finished[1:]
+	}
+}
+
+func (s *Server) remove(id string) {
+	s.mu.Lock()
+	j := s.jobs[id]
+	delete(s.jobs, id)
+	for i, o := range s.order {
+		if o == id {
+			s.order = append(s.order[:i], s.order[i+1:]...)
+			break
+		}
+	}
+	s.mu.Unlock()
+	if j != nil {
+		_ = os.RemoveAll(j.dir)
+	}
+}
+
+func (j *job) setRunning() {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	now := time.Now().UTC()
+	j.status.Status = StatusRunning
+	j.status.Started = &now
+}
+
+func (j *job) setProgress(p matcher.Progress) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.status.Progress = &p
+}
+
+func (j *job) finish(result any, err error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	now := time.Now().UTC()
+	j.status.Finished = &now
+	switch {
+	case err == nil:
+		j.status.Status = StatusSucceeded
+		j.status.Result = result
+	case errors.Is(err, context.Canceled):
+		j.status.Status = StatusCanceled
+	default:
+		j.status.Status = StatusFailed
+		j.status.Code = errorCode(err)
+		j.status.Error = err.Error()
+	}
+}
+
+func (j *job) cancel() {
+	j.stop()
+}
+
+func (j *job) finished() bool {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.status.Finished != nil
+}
+
+func (j *job) snapshot(withResult bool) JobStatus {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	st := j.status
+	if !withResult {
+		st.Result = nil
+	}
+	return st
+}
+
+func newJobID() string {
+	var b [8]byte
+	_, _ = rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
+
+// errorCodes maps the matcher's errors to the codes of failed jobs, in the
+// order they are checked.
+var errorCodes = []struct {
+	err  error
+	code string
+}{
+	{matcher.ErrMissingJD, "missing_jd"},
+	{matcher.ErrReadJD, "read_jd"},
+	{matcher.ErrMissingResumes, "missing_resumes"},
+	{matcher.ErrListResumes, "list_resumes"},
+	{matcher.ErrNoResumes, "no_resumes"},
+	{matcher.ErrMissingResume, "missing_resume"},
+	{matcher.ErrReadResume, "read_resume"},
+	{matcher.ErrLoadConfig, "config"},
+	{matcher.ErrLoadTaxonomy, "taxonomy"},
+	{matcher.ErrLoadProfiles, "profile"},
+	{matcher.ErrUnknownProfile, "profile"},
+	{matcher.ErrInvalidWeights, "profile"},
+	{matcher.ErrLoadRules, "rules"},
+	{matcher.ErrKnockoutMode, "rules"},
+	{matcher.ErrFormat, "format"},
+	{matcher.ErrLogLevel, "log_level"},
+	{matcher.ErrWriteResults, "write_results"},
+	{matcher.ErrMissingOpenAIKey, "missing_api_key"},
+	{matcher.ErrProvider, "provider"},
+	{matcher.ErrProviderAuth, "api_auth"},
+	{matcher.ErrProviderQuota, "api_quota"},
+	{matcher.ErrProviderRateLimited, "api_rate_limited"},
+	{matcher.ErrProviderBadRequest, "api_bad_request"},
+	{matcher.ErrProviderUnavailable, "api_unavailable"},
+}
+
+func errorCode(err error) string {
+	for _, c := range errorCodes {
+		if errors.Is(err, c.err) {
+			return c.code
+		}
+	}
+	return "internal"
+}
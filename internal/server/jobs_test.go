@@ -0,0 +1,34 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"resume-gpt/internal/matcher"
+)
+
+func TestErrorCode(t *testing.T) {
+	for _, c := range errorCodes {
+		err := fmt.Errorf("run: %w", fmt.Errorf("%w: detail", c.err))
+		if got := errorCode(err); got != c.code {
+			t.Errorf("errorCode(%v) = %q, want %q", c.err, got, c.code)
+		}
+	}
+
+	tests := []struct {
+		err  error
+		want string
+	}{
+		{fmt.Errorf("%w: 401", matcher.ErrProviderAuth), "api_auth"},
+		{fmt.Errorf("%w: unknown provider", matcher.ErrProvider), "provider"},
+		{errors.New("disk on fire"), "internal"},
+		{context.DeadlineExceeded, "internal"},
+	}
+	for _, tt := range tests {
+		if got := errorCode(tt.err); got != tt.want {
+			t.Errorf("errorCode(%v) = %q, want %q", tt.err, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,360 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"resume-gpt/internal/matcher"
+)
+
+var (
+	errBadRequest      = errors.New("bad request")
+	errUnsupportedType = errors.New("unsupported content type (want application/json, multipart/form-data or a url-encoded form)")
+)
+
+// maxFieldBytes limits a single non-file field of a multipart form.
+const maxFieldBytes = 1 << 20
+
+// runRequest is the body of POST /v1/run and /v1/run-heuristic. Paths are
+// read on the server; a multipart request can upload the JD as "jd" and the
+// resumes as one or more "resumes" files instead, and sends the other fields
+// as form values.
+type runRequest struct {
+	JDPath         string   `json:"jdPath"`
+	ResumesDir     string   `json:"resumesDir"`
+	TopN           int      `json:"topN"`
+	TaxonomyPath   string   `json:"taxonomyPath"`
+	ProfilesPath   string   `json:"profilesPath"`
+	Profile        string   `json:"profile"`
+	Weights        string   `json:"weights"`
+	RulesPath      string   `json:"rulesPath"`
+	KnockoutFromJD bool     `json:"knockoutFromJD"`
+	KnockoutMode   string   `json:"knockoutMode"`
+	Formats        []string `json:"formats"`
+	Report         string   `json:"report"`
+	LogLevel       string   `json:"logLevel"`
+}
+
+// evaluateRequest is the body of POST /v1/evaluate; "jd" and "resume" files
+// replace the paths in a multipart request.
+type evaluateRequest struct {
+	JDPath     string `json:"jdPath"`
+	ResumePath string `json:"resumePath"`
+}
+
+// matchJobsRequest is the body of POST /v1/match-jobs: one resume against a
+// list of JD files and folders. A multipart request can upload the resume as
+// "resume" and the JDs as one or more "jds" files instead.
+type matchJobsRequest struct {
+	ResumePath     string   `json:"resumePath"`
+	JDPaths        []string `json:"jdPaths"`
+	Heuristic      bool     `json:"heuristic"`
+	TopN           int      `json:"topN"`
+	TaxonomyPath   string   `json:"taxonomyPath"`
+	ProfilesPath   string   `json:"profilesPath"`
+	Profile        string   `json:"profile"`
+	Weights        string   `json:"weights"`
+	RulesPath      string   `json:"rulesPath"`
+	KnockoutFromJD bool     `json:"knockoutFromJD"`
+	KnockoutMode   string   `json:"knockoutMode"`
+}
+
+func (req *runRequest) setField(name, value string) error {
+	var err error
+	switch name {
+	case "jdPath":
+		req.JDPath = value
+	case "resumesDir":
+		req.ResumesDir = value
+	case "topN":
+		req.TopN, err = strconv.Atoi(strings.TrimSpace(value))
+	case "taxonomyPath":
+		req.TaxonomyPath = value
+	case "profilesPath":
+		req.ProfilesPath = value
+	case "profile":
+		req.Profile = value
+	case "weights":
+		req.Weights = value
+	case "rulesPath":
+		req.RulesPath = value
+	case "knockoutFromJD":
+		req.KnockoutFromJD, err = strconv.ParseBool(strings.TrimSpace(value))
+	case "knockoutMode":
+		req.KnockoutMode = value
+	case "formats":
+		var formats []string
+		formats, err = matcher.ParseFormats(value)
+		req.Formats = append(req.Formats, formats...)
+	case "report":
+		req.Report = value
+	case "logLevel":
+		req.LogLevel = value
+	default:
+		return fmt.Errorf("%w: unknown field %q", errBadRequest, name)
+	}
+	if err != nil {
+		return fmt.Errorf("%w: %s: %v", errBadRequest, name, err)
+	}
+	return nil
+}
+
+func (req *evaluateRequest) setField(name, value string) error {
+	switch name {
+	case "jdPath":
+		req.JDPath = value
+	case "resumePath":
+		req.ResumePath = value
+	default:
+		return fmt.Errorf("%w: unknown field %q", errBadRequest, name)
+	}
+	return nil
+}
+
+func (req *matchJobsRequest) setField(name, value string) error {
+	var err error
+	switch name {
+	case "resumePath":
+		req.ResumePath = value
+	case "jdPaths":
+		for _, p := range strings.Split(value, ",") {
+			if p = strings.TrimSpace(p); p != "" {
+				req.JDPaths = append(req.JDPaths, p)
+			}
+		}
+	case "heuristic":
+		req.Heuristic, err = strconv.ParseBool(strings.TrimSpace(value))
+	case "topN":
+		req.TopN, err = strconv.Atoi(strings.TrimSpace(value))
+	case "taxonomyPath":
+		req.TaxonomyPath = value
+	case "profilesPath":
+		req.ProfilesPath = value
+	case "profile":
+		req.Profile = value
+	case "weights":
+		req.Weights = value
+	case "rulesPath":
+		req.RulesPath = value
+	case "knockoutFromJD":
+		req.KnockoutFromJD, err = strconv.ParseBool(strings.TrimSpace(value))
+	case "knockoutMode":
+		req.KnockoutMode = value
+	default:
+		return fmt.Errorf("%w: unknown field %q", errBadRequest, name)
+	}
+	if err != nil {
+		return fmt.Errorf("%w: %s: %v", errBadRequest, name, err)
+	}
+	return nil
+}
+
+func (req runRequest) input(outPath string) matcher.Input {
+	return matcher.Input{
+		JDPath:         req.JDPath,
+		ResumesDir:     req.ResumesDir,
+		TopN:           req.TopN,
+		OutPath:        outPath,
+		TaxonomyPath:   req.TaxonomyPath,
+		ProfilesPath:   req.ProfilesPath,
+		Profile:        req.Profile,
+		Weights:        req.Weights,
+		RulesPath:      req.RulesPath,
+		KnockoutFromJD: req.KnockoutFromJD,
+		KnockoutMode:   req.KnockoutMode,
+		Formats:        req.Formats,
+		Report:         req.Report,
+		LogLevel:       req.LogLevel,
+	}
+}
+
+// useUploads points the request at the uploaded JD and resumes folder.
+func (req *runRequest) useUploads(files map[string][]string, dir string) error {
+	if len(files["jd"]) > 1 {
+		return fmt.Errorf("%w: upload a single jd file", errBadRequest)
+	}
+	if len(files["jd"]) == 1 {
+		req.JDPath = files["jd"][0]
+	}
+	if len(files["resumes"]) > 0 {
+		req.ResumesDir = filepath.Join(dir, "resumes")
+	}
+	if strings.TrimSpace(req.JDPath) == "" || strings.TrimSpace(req.ResumesDir) == "" {
+		return fmt.Errorf("%w: send jdPath and resumesDir, or upload jd and resumes files", errBadRequest)
+	}
+	return nil
+}
+
+// useUploads points the request at the uploaded JD and resume.
+func (req *evaluateRequest) useUploads(files map[string][]string) error {
+	if len(files["jd"]) > 1 || len(files["resume"]) > 1 {
+		return fmt.Errorf("%w: upload a single jd and a single resume file", errBadRequest)
+	}
+	if len(files["jd"]) == 1 {
+		req.JDPath = files["jd"][0]
+	}
+	if len(files["resume"]) == 1 {
+		req.ResumePath = files["resume"][0]
+	}
+	if strings.TrimSpace(req.JDPath) == "" || strings.TrimSpace(req.ResumePath) == "" {
+		return fmt.Errorf("%w: send jdPath and resumePath, or upload jd and resume files", errBadRequest)
+	}
+	return nil
+}
+
+func (req matchJobsRequest) input() matcher.JobsInput {
+	return matcher.JobsInput{
+		Input: matcher.Input{
+			TopN:           req.TopN,
+			TaxonomyPath:   req.TaxonomyPath,
+			ProfilesPath:   req.ProfilesPath,
+			Profile:        req.Profile,
+			Weights:        req.Weights,
+			RulesPath:      req.RulesPath,
+			KnockoutFromJD: req.KnockoutFromJD,
+			KnockoutMode:   req.KnockoutMode,
+		},
+		ResumePath: req.ResumePath,
+		JDPaths:    req.JDPaths,
+	}
+}
+
+// useUploads points the request at the uploaded resume and JDs folder.
+func (req *matchJobsRequest) useUploads(files map[string][]string, dir string) error {
+	if len(files["resume"]) > 1 {
+		return fmt.Errorf("%w: upload a single resume file", errBadRequest)
+	}
+	if len(files["resume"]) == 1 {
+		req.ResumePath = files["resume"][0]
+	}
+	if len(files["jds"]) > 0 {
+		req.JDPaths = append(req.JDPaths, filepath.Join(dir, "jds"))
+	}
+	if strings.TrimSpace(req.ResumePath) == "" || len(req.JDPaths) == 0 {
+		return fmt.Errorf("%w: send resumePath and jdPaths, or upload resume and jds files", errBadRequest)
+	}
+	return nil
+}
+
+type fieldSetter interface {
+	setField(name, value string) error
+}
+
+// decodeRequest reads a JSON body or form into req. The files of a multipart
+// form are saved to dir/<field>/<name>. It returns the saved files by field; only
+// the fields listed in fileFields may carry files.
+func decodeRequest(r *http.Request, req fieldSetter, dir string, fileFields ...string) (map[string][]string, error) {
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil && r.Header.Get("Content-Type") != "" {
+		return nil, errUnsupportedType
+	}
+	switch mediaType {
+	case "", "application/json":
+		dec := json.NewDecoder(r.Body)
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(req); err != nil {
+			return nil, fmt.Errorf("%w: %w", errBadRequest, err)
+		}
+		return nil, nil
+	case "application/x-www-form-urlencoded":
+		if err := r.ParseForm(); err != nil {
+			return nil, fmt.Errorf("%w: %w", errBadRequest, err)
+		}
+		for name, values := range r.PostForm {
+			for _, v := range values {
+				if err := req.setField(name, v); err != nil {
+					return nil, err
+				}
+			}
+		}
+		return nil, nil
+	case "multipart/form-data":
+	default:
+		return nil, errUnsupportedType
+	}
+
+	mr, err := r.MultipartReader()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", errBadRequest, err)
+	}
+	files := map[string][]string{}
+	for {
+		part, err := mr.NextPart()
+		if err == io.EOF {
+			return files, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", errBadRequest, err)
+		}
+		name := part.FormName()
+		if part.FileName() == "" {
+			value, err := io.ReadAll(io.LimitReader(part, maxFieldBytes+1))
+			if err != nil {
+				return nil, fmt.Errorf("%w: %w", errBadRequest, err)
+			}
+			if len(value) > maxFieldBytes {
+				return nil, fmt.Errorf("%w: field %q is larger than %d bytes", errBadRequest, name, maxFieldBytes)
+			}
+			if err := req.setField(name, string(value)); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if !slices.Contains(fileFields, name) {
+			return nil, fmt.Errorf("%w: field %q does not take files (want %s)", errBadRequest, name, strings.Join(fileFields, " or "))
+		}
+		path, err := saveUpload(filepath.Join(dir, name), part.FileName(), part)
+		if err != nil {
+			return nil, err
+		}
+		files[name] = append(files[name], path)
+	}
+}
+
+// saveUpload writes an uploaded file to dir under its base name, so a client
+// cannot place it anywhere else.
+func saveUpload(dir, name string, r io.Reader) (string, error) {
+	base := filepath.Base(filepath.Clean("/" + strings.ReplaceAll(name, "\\", "/")))
+	if base == "/" || base == "." || strings.HasPrefix(base, ".") {
+		return "", fmt.Errorf("%w: invalid file name %q", errBadRequest, name)
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+	path := filepath.Join(dir, base)
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+	if errors.Is(err, os.ErrExist) {
+		return "", fmt.Errorf("%w: file %q uploaded twice", errBadRequest, base)
+	}
+	if err != nil {
+		return "", err
+	}
+	if _, err := io.Copy(f, r); err != nil {
+		f.Close()
+		return "", err
+	}
+	return path, f.Close()
+}
+
+// requestStatus is the HTTP status for an error reading a request.
+func requestStatus(err error) int {
+	var tooLarge *http.MaxBytesError
+	switch {
+	case errors.As(err, &tooLarge):
+		return http.StatusRequestEntityTooLarge
+	case errors.Is(err, errUnsupportedType):
+		return http.StatusUnsupportedMediaType
+	case errors.Is(err, errBadRequest):
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
+}
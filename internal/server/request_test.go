@@ -0,0 +1,59 @@
+package server
+
+import (
+	"bytes"
+	"errors"
+	"mime/multipart"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSaveUpload(t *testing.T) {
+	dir := t.TempDir()
+	path, err := saveUpload(dir, "../x", strings.NewReader("resume"))
+	if err != nil {
+		t.Fatalf("../x: %v", err)
+	}
+	if want := filepath.Join(dir, "x"); path != want {
+		t.Errorf("../x saved to %s, want %s", path, want)
+	}
+
+	for _, name := range []string{".env", "../.ssh", `..\.bashrc`, "..", "/"} {
+		if _, err := saveUpload(dir, name, strings.NewReader("x")); !errors.Is(err, errBadRequest) {
+			t.Errorf("saveUpload(%q) error = %v, want errBadRequest", name, err)
+		}
+	}
+
+	if _, err := saveUpload(dir, "sub/x", strings.NewReader("other")); !errors.Is(err, errBadRequest) {
+		t.Errorf("duplicate name error = %v, want errBadRequest", err)
+	}
+	if data, _ := os.ReadFile(filepath.Join(dir, "x")); string(data) != "resume" {
+		t.Errorf("duplicate upload overwrote the first: %q", data)
+	}
+	entries, _ := os.ReadDir(filepath.Dir(dir))
+	for _, e := range entries {
+		if e.Name() == "x" {
+			t.Error("../x was written outside dir")
+		}
+	}
+}
+
+func TestDecodeRequestFieldTooLarge(t *testing.T) {
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	_ = mw.WriteField("jdPath", strings.Repeat("x", maxFieldBytes+1))
+	_ = mw.Close()
+	r := httptest.NewRequest("POST", "/v1/evaluate", &body)
+	r.Header.Set("Content-Type", mw.FormDataContentType())
+
+	var req evaluateRequest
+	if _, err := decodeRequest(r, &req, t.TempDir(), "jd", "resume"); !errors.Is(err, errBadRequest) {
+		t.Errorf("error = %v, want errBadRequest", err)
+	}
+	if req.JDPath != "" {
+		t.Errorf("JDPath was set to %d bytes", len(req.JDPath))
+	}
+}
@@ -0,0 +1,295 @@
+// Package server exposes the matcher as a local HTTP API. Every call except
+// the health check needs the bearer token; runs and evaluations are queued as
+// jobs that clients poll and can cancel.
+package server
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"resume-gpt/internal/matcher"
+)
+
+var ErrNoToken = errors.New("server token not set")
+
+// Config holds the settings of a Server. Zero values use the defaults below.
+type Config struct {
+	// Token is required as "Authorization: Bearer <token>".
+	Token string
+	// WorkDir holds one folder per job with its uploads and result files.
+	WorkDir string
+	// MaxRequestBytes limits a request body, uploads included.
+	MaxRequestBytes int64
+	// MaxConcurrent is how many jobs run at once; the rest wait queued.
+	MaxConcurrent int
+	// KeepJobs is how many finished jobs are kept for polling. Older ones
+	// are dropped together with their folders.
+	KeepJobs int
+	// Log receives a record per job; nil logs nothing.
+	Log *slog.Logger
+}
+
+const (
+	defaultMaxRequestBytes = 256 << 20
+	defaultMaxConcurrent   = 2
+	defaultKeepJobs        = 100
+)
+
+// Server is an http.Handler serving the /v1 API.
+type Server struct {
+	cfg  Config
+	mux  *http.ServeMux
+	log  *slog.Logger
+	ctx  context.Context
+	stop context.CancelFunc
+	wg   sync.WaitGroup
+	// slots bounds the running jobs.
+	slots chan struct{}
+
+	mu   sync.Mutex
+	jobs map[string]*job
+	// order lists job IDs oldest first.
+	order []string
+}
+
+// New checks cfg and creates its work folder.
+func New(cfg Config) (*Server, error) {
+	if strings.TrimSpace(cfg.Token) == "" {
+		return nil, ErrNoToken
+	}
+	if cfg.WorkDir == "" {
+		cfg.WorkDir = filepath.Join(os.TempDir(), "resumegpt-serve")
+	}
+	dir, err := filepath.Abs(cfg.WorkDir)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	cfg.WorkDir = dir
+	if cfg.MaxRequestBytes <= 0 {
+		cfg.MaxRequestBytes = defaultMaxRequestBytes
+	}
+	if cfg.MaxConcurrent <= 0 {
+		cfg.MaxConcurrent = defaultMaxConcurrent
+	}
+	if cfg.KeepJobs <= 0 {
+		cfg.KeepJobs = defaultKeepJobs
+	}
+	log := cfg.Log
+	if log == nil {
+		log = slog.New(slog.DiscardHandler)
+	}
+
+	ctx, stop := context.WithCancel(context.Background())
+	s := &Server{
+		cfg:   cfg,
+		mux:   http.NewServeMux(),
+		log:   log,
+		ctx:   ctx,
+		stop:  stop,
+		slots: make(chan struct{}, cfg.MaxConcurrent),
+		jobs:  map[string]*job{},
+	}
+	s.mux.HandleFunc("GET /v1/health", s.handleHealth)
+	s.mux.HandleFunc("POST /v1/run", s.handleRun(KindRun))
+	s.mux.HandleFunc("POST /v1/run-heuristic", s.handleRun(KindRunHeuristic))
+	s.mux.HandleFunc("POST /v1/evaluate", s.handleEvaluate)
+	s.mux.HandleFunc("POST /v1/match-jobs", s.handleMatchJobs)
+	s.mux.HandleFunc("GET /v1/jobs", s.handleListJobs)
+	s.mux.HandleFunc("GET /v1/jobs/{id}", s.handleGetJob)
+	s.mux.HandleFunc("POST /v1/jobs/{id}/cancel", s.handleCancelJob)
+	s.mux.HandleFunc("DELETE /v1/jobs/{id}", s.handleDeleteJob)
+	return s, nil
+}
+
+// WorkDir is the absolute folder the jobs are kept in.
+func (s *Server) WorkDir() string {
+	return s.cfg.WorkDir
+}
+
+// Close cancels every queued or running job and waits for them to stop.
+func (s *Server) Close() {
+	s.stop()
+	s.wg.Wait()
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/v1/health" && !s.authorized(r) {
+		w.Header().Set("WWW-Authenticate", `Bearer realm="resumegpt"`)
+		writeError(w, http.StatusUnauthorized, "missing or invalid token")
+		return
+	}
+	r.Body = http.MaxBytesReader(w, r.Body, s.cfg.MaxRequestBytes)
+	s.mux.ServeHTTP(w, r)
+}
+
+func (s *Server) authorized(r *http.Request) bool {
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(strings.TrimSpace(token)), []byte(s.cfg.Token)) == 1
+}
+
+func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+// handleRun queues a ranking run. Results are written to the job's folder
+// and the job's result is the matcher.Output.
+func (s *Server) handleRun(kind string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id := newJobID()
+		dir := filepath.Join(s.cfg.WorkDir, id)
+		var req runRequest
+		files, err := decodeRequest(r, &req, dir, "jd", "resumes")
+		if err == nil {
+			err = req.useUploads(files, dir)
+		}
+		if err != nil {
+			_ = os.RemoveAll(dir)
+			writeError(w, requestStatus(err), err.Error())
+			return
+		}
+
+		input := req.input(filepath.Join(dir, "results.csv"))
+		j := s.submit(id, kind, dir, func(ctx context.Context, progress matcher.ProgressFunc) (any, error) {
+			input.Progress = progress
+			if kind == KindRunHeuristic {
+				return matcher.RunHeuristic(ctx, input)
+			}
+			return matcher.Run(ctx, input)
+		})
+		writeAccepted(w, j)
+	}
+}
+
+// handleEvaluate queues an evaluation of one resume; the job's result is the
+// matcher.ResumeAnalysis.
+func (s *Server) handleEvaluate(w http.ResponseWriter, r *http.Request) {
+	id := newJobID()
+	dir := filepath.Join(s.cfg.WorkDir, id)
+	var req evaluateRequest
+	files, err := decodeRequest(r, &req, dir, "jd", "resume")
+	if err == nil {
+		err = req.useUploads(files)
+	}
+	if err != nil {
+		_ = os.RemoveAll(dir)
+		writeError(w, requestStatus(err), err.Error())
+		return
+	}
+
+	j := s.submit(id, KindEvaluate, dir, func(ctx context.Context, _ matcher.ProgressFunc) (any, error) {
+		return matcher.EvaluateCandidate(ctx, req.JDPath, req.ResumePath)
+	})
+	writeAccepted(w, j)
+}
+
+// handleMatchJobs queues a ranking of JDs for one resume; the job's result
+// is the matcher.JobsOutput.
+func (s *Server) handleMatchJobs(w http.ResponseWriter, r *http.Request) {
+	id := newJobID()
+	dir := filepath.Join(s.cfg.WorkDir, id)
+	var req matchJobsRequest
+	files, err := decodeRequest(r, &req, dir, "resume", "jds")
+	if err == nil {
+		err = req.useUploads(files, dir)
+	}
+	if err != nil {
+		_ = os.RemoveAll(dir)
+		writeError(w, requestStatus(err), err.Error())
+		return
+	}
+
+	input := req.input()
+	j := s.submit(id, KindMatchJobs, dir, func(ctx context.Context, progress matcher.ProgressFunc) (any, error) {
+		input.Progress = progress
+		if req.Heuristic {
+			return matcher.MatchJobsHeuristic(ctx, input)
+		}
+		return matcher.MatchJobs(ctx, input)
+	})
+	writeAccepted(w, j)
+}
+
+func writeAccepted(w http.ResponseWriter, j *job) {
+	st := j.snapshot(false)
+	w.Header().Set("Location", "/v1/jobs/"+st.ID)
+	writeJSON(w, http.StatusAccepted, st)
+}
+
+func (s *Server) handleListJobs(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	list := make([]JobStatus, 0, len(s.order))
+	for i := len(s.order) - 1; i >= 0; i-- {
+		list = append(list, s.jobs[s.order[i]].snapshot(false))
+	}
+	s.mu.Unlock()
+	writeJSON(w, http.StatusOK, list)
+}
+
+func (s *Server) handleGetJob(w http.ResponseWriter, r *http.Request) {
+	j := s.job(r.PathValue("id"))
+	if j == nil {
+		writeError(w, http.StatusNotFound, "job not found")
+		return
+	}
+	writeJSON(w, http.StatusOK, j.snapshot(true))
+}
+
+// handleCancelJob stops a queued or running job; finished jobs are left as
+// they are.
+func (s *Server) handleCancelJob(w http.ResponseWriter, r *http.Request) {
+	j := s.job(r.PathValue("id"))
+	if j == nil {
+		writeError(w, http.StatusNotFound, "job not found")
+		return
+	}
+	j.cancel()
+	writeJSON(w, http.StatusOK, j.snapshot(false))
+}
+
+// handleDeleteJob drops a finished job and its folder.
+func (s *Server) handleDeleteJob(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	j := s.job(id)
+	if j == nil {
+		writeError(w, http.StatusNotFound, "job not found")
+		return
+	}
+	if !j.finished() {
+		writeError(w, http.StatusConflict, "job is still "+j.snapshot(false).Status+"; cancel it first")
+		return
+	}
+	s.remove(id)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) job(id string) *job {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.jobs[id]
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	_ = enc.Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, msg string) {
+	writeJSON(w, status, map[string]string{"error": msg})
+}
@@ -0,0 +1,140 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"resume-gpt/internal/matcher"
+)
+
+const testToken = "secret"
+
+func newTestServer(t *testing.T, cfg Config) *Server {
+	t.Helper()
+	cfg.Token = testToken
+	cfg.WorkDir = t.TempDir()
+	s, err := New(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(s.Close)
+	return s
+}
+
+// do sends a request with the test token unless auth is empty, which sends
+// none.
+func do(s *Server, method, path, auth, body string) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(method, path, strings.NewReader(body))
+	if body != "" {
+		r.Header.Set("Content-Type", "application/json")
+	}
+	if auth != "" {
+		r.Header.Set("Authorization", auth)
+	}
+	w := httptest.NewRecorder()
+	s.ServeHTTP(w, r)
+	return w
+}
+
+// blockingJob submits a job that runs until it is canceled.
+func blockingJob(s *Server) *job {
+	id := newJobID()
+	return s.submit(id, KindRun, filepath.Join(s.cfg.WorkDir, id), func(ctx context.Context, _ matcher.ProgressFunc) (any, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+}
+
+func waitStatus(t *testing.T, s *Server, id, want string) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		w := do(s, http.MethodGet, "/v1/jobs/"+id, "Bearer "+testToken, "")
+		var st JobStatus
+		if err := json.Unmarshal(w.Body.Bytes(), &st); err != nil {
+			t.Fatalf("GET job: %v: %s", err, w.Body)
+		}
+		if st.Status == want {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("job %s is %s, want %s", id, st.Status, want)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestAuth(t *testing.T) {
+	s := newTestServer(t, Config{})
+	tests := []struct {
+		name, path, auth string
+		want             int
+	}{
+		{"no token", "/v1/jobs", "", http.StatusUnauthorized},
+		{"wrong token", "/v1/jobs", "Bearer nope", http.StatusUnauthorized},
+		{"not bearer", "/v1/jobs", testToken, http.StatusUnauthorized},
+		{"token", "/v1/jobs", "Bearer " + testToken, http.StatusOK},
+		{"health needs none", "/v1/health", "", http.StatusOK},
+	}
+	for _, tt := range tests {
+		w := do(s, http.MethodGet, tt.path, tt.auth, "")
+		if w.Code != tt.want {
+			t.Errorf("%s: status = %d, want %d", tt.name, w.Code, tt.want)
+		}
+		if w.Code == http.StatusUnauthorized && w.Header().Get("WWW-Authenticate") == "" {
+			t.Errorf("%s: no WWW-Authenticate header", tt.name)
+		}
+	}
+}
+
+func TestRequestTooLarge(t *testing.T) {
+	s := newTestServer(t, Config{MaxRequestBytes: 64})
+	body := `{"jdPath": "` + strings.Repeat("x", 100) + `", "resumesDir": "r"}`
+	w := do(s, http.MethodPost, "/v1/run", "Bearer "+testToken, body)
+	if w.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("status = %d, want %d: %s", w.Code, http.StatusRequestEntityTooLarge, w.Body)
+	}
+}
+
+func TestCancelQueuedJob(t *testing.T) {
+	s := newTestServer(t, Config{MaxConcurrent: 1})
+	running := blockingJob(s)
+	waitStatus(t, s, running.snapshot(false).ID, StatusRunning)
+	queued := blockingJob(s).snapshot(false).ID
+	waitStatus(t, s, queued, StatusQueued)
+
+	w := do(s, http.MethodPost, "/v1/jobs/"+queued+"/cancel", "Bearer "+testToken, "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("cancel status = %d, want 200: %s", w.Code, w.Body)
+	}
+	waitStatus(t, s, queued, StatusCanceled)
+	if st := running.snapshot(false); st.Status != StatusRunning {
+		t.Errorf("running job became %s after canceling another", st.Status)
+	}
+}
+
+func TestDeleteRunningJob(t *testing.T) {
+	s := newTestServer(t, Config{})
+	id := blockingJob(s).snapshot(false).ID
+	waitStatus(t, s, id, StatusRunning)
+
+	w := do(s, http.MethodDelete, "/v1/jobs/"+id, "Bearer "+testToken, "")
+	if w.Code != http.StatusConflict {
+		t.Fatalf("delete running: status = %d, want %d", w.Code, http.StatusConflict)
+	}
+
+	do(s, http.MethodPost, "/v1/jobs/"+id+"/cancel", "Bearer "+testToken, "")
+	waitStatus(t, s, id, StatusCanceled)
+	if w := do(s, http.MethodDelete, "/v1/jobs/"+id, "Bearer "+testToken, ""); w.Code != http.StatusNoContent {
+		t.Errorf("delete canceled: status = %d, want %d", w.Code, http.StatusNoContent)
+	}
+	if w := do(s, http.MethodGet, "/v1/jobs/"+id, "Bearer "+testToken, ""); w.Code != http.StatusNotFound {
+		t.Errorf("get deleted: status = %d, want %d", w.Code, http.StatusNotFound)
+	}
+}
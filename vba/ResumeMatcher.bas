@@ -46,6 +46,7 @@ Public Sub RunMatcher()
     Dim resumesPath As String
     Dim stdErr As String
     Dim stdOut As String
+    Dim skippedCount As Long
 
     Set wsInputs = Sheets("Inputs")
     Set wsResults = Sheets("Results")
@@ -120,9 +121,18 @@ Public Sub RunMatcher()
         Exit Sub
     End If
 
-    wsInputs.Range("B6").Value = "Completed"
     ImportResultsCSV wsResults, outCsv
-    MsgBox "Done. Results are in the Results sheet.", vbInformation
+    skippedCount = ImportSkippedCSV(wsResults, SkippedCsvPath(outCsv))
+    If skippedCount > 0 Then
+        wsInputs.Range("B6").Value = "Completed (" & skippedCount & " skipped)"
+    Else
+        wsInputs.Range("B6").Value = "Completed"
+    End If
+    If Trim(stdErr) <> "" Then
+        MsgBox "Done with warnings. Results are in the Results sheet." & vbCrLf & vbCrLf & stdErr, vbExclamation
+    Else
+        MsgBox "Done. Results are in the Results sheet.", vbInformation
+    End If
     Exit Sub
 
 ExecFailed:
@@ -175,3 +185,43 @@ Private Sub ImportResultsCSV(ByVal ws As Worksheet, ByVal csvPath As String)
         .Delete
     End With
 End Sub
+
+Private Function SkippedCsvPath(ByVal csvPath As String) As String
+    Dim pos As Long
+    pos = InStrRev(csvPath, "\")
+    If pos = 0 Then
+        SkippedCsvPath = "skipped.csv"
+    Else
+        SkippedCsvPath = Left(csvPath, pos) & "skipped.csv"
+    End If
+End Function
+
+' Appends the files the matcher could not read below the results and returns
+' how many there were.
+Private Function ImportSkippedCSV(ByVal ws As Worksheet, ByVal csvPath As String) As Long
+    Dim startRow As Long
+    Dim lastRow As Long
+
+    ImportSkippedCSV = 0
+    If Dir(csvPath) = "" Then
+        Exit Function
+    End If
+
+    startRow = ws.Cells(ws.Rows.Count, 1).End(xlUp).Row + 2
+    ws.Cells(startRow, 1).Value = "Skipped files"
+    ws.Cells(startRow, 1).Font.Bold = True
+
+    With ws.QueryTables.Add(Connection:="TEXT;" & csvPath, Destination:=ws.Cells(startRow + 1, 1))
+        .TextFileParseType = xlDelimited
+        .TextFileCommaDelimiter = True
+        .TextFileTextQualifier = xlTextQualifierDoubleQuote
+        .Refresh BackgroundQuery:=False
+        .Delete
+    End With
+
+    lastRow = ws.Cells(ws.Rows.Count, 1).End(xlUp).Row
+    ImportSkippedCSV = lastRow - (startRow + 1)
+    If ImportSkippedCSV = 0 Then
+        ws.Range(ws.Cells(startRow, 1), ws.Cells(startRow + 1, 2)).Clear
+    End If
+End Function